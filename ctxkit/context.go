@@ -12,8 +12,32 @@ const (
 	// RequestID represents a Key for context by which
 	// the request ID can be received from the context.
 	requestID Key = "ctx.request-id"
+
+	// peerIdentity represents a Key for context by which
+	// the verified peer identity can be received from the context.
+	peerIdentity Key = "ctx.peer-identity"
 )
 
+// PeerIdentity represents an identity of the peer
+// verified by the client TLS certificate.
+type PeerIdentity struct {
+	// Subject represents the certificate subject distinguished name.
+	Subject string
+
+	// CommonName represents the certificate subject common name.
+	CommonName string
+
+	// DNSNames represents the certificate DNS subject alternative names.
+	DNSNames []string
+
+	// URIs represents the certificate URI subject alternative names.
+	URIs []string
+
+	// SPIFFEID represents the SPIFFE ID of the peer if certificate has one.
+	// See: https://github.com/spiffe/spiffe/blob/main/standards/X509-SVID.md
+	SPIFFEID string
+}
+
 // Key represents a context Key with custom type.
 type Key string
 
@@ -63,5 +87,20 @@ func GetRequestID(ctx context.Context) string {
 	return ""
 }
 
+// SetPeerIdentity sets the verified peer identity to the context.
+func SetPeerIdentity(ctx context.Context, id *PeerIdentity) context.Context {
+	return context.WithValue(ctx, peerIdentity, id)
+}
+
+// GetPeerIdentity gets the verified peer identity from the context.
+// If searched values is absent in context, then nil wil be returned.
+func GetPeerIdentity(ctx context.Context) *PeerIdentity {
+	if id, ok := ctx.Value(peerIdentity).(*PeerIdentity); ok {
+		return id
+	}
+
+	return nil
+}
+
 // zero returns default zeroed value for type T.
 func zero[T any]() (v T) { return v }
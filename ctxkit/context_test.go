@@ -35,6 +35,21 @@ func TestSetRequestID(t *testing.T) {
 	td.Cmp(t, got, want)
 }
 
+func TestGetPeerIdentity(t *testing.T) {
+	want := &PeerIdentity{CommonName: "test"}
+	ctx := context.WithValue(context.Background(), peerIdentity, want)
+	got := GetPeerIdentity(ctx)
+	td.Cmp(t, got, td.Shallow(want))
+	td.Cmp(t, GetPeerIdentity(context.Background()), td.Nil())
+}
+
+func TestSetPeerIdentity(t *testing.T) {
+	want := &PeerIdentity{CommonName: "test"}
+	ctx := SetPeerIdentity(context.Background(), want)
+	got := ctx.Value(peerIdentity)
+	td.Cmp(t, got, td.Shallow(want))
+}
+
 func TestSet(t *testing.T) {
 	want := "test"
 	ctx := Set[string](context.Background(), "ctx.str", want)
@@ -37,6 +37,19 @@ func (c *Conn) Close() error {
 	return nil
 }
 
+// Start checks the connection, which makes Conn the component managed by servekit.Run.
+func (c *Conn) Start(ctx context.Context) error { return c.HealthCheck(ctx) }
+
+// Stop disconnects the client. Waits for the in use connections
+// to be returned to the pool or the ctx to be done.
+func (c *Conn) Stop(ctx context.Context) error {
+	if err := c.Disconnect(ctx); err != nil {
+		return fmt.Errorf("mongo: disconnect: %w", err)
+	}
+
+	return nil
+}
+
 // HealthCheck implements the health.Checker interface for MongoDB connection.
 func (c *Conn) HealthCheck(ctx context.Context) error {
 	prefs, err := readpref.New(readpref.PrimaryPreferredMode)
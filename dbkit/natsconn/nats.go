@@ -3,6 +3,7 @@ package natsconn
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/nats-io/nats.go"
 )
@@ -18,6 +19,33 @@ func (c *Conn) HealthCheck(_ context.Context) error {
 	return fmt.Errorf("nats: connection failed: %s", c.Status())
 }
 
+// Start checks the connection, which makes Conn the component managed by servekit.Run.
+func (c *Conn) Start(ctx context.Context) error { return c.HealthCheck(ctx) }
+
+// Stop drains the connection, so the pending messages are processed
+// before the connection is closed. The connection is closed when the ctx is done.
+func (c *Conn) Stop(ctx context.Context) error {
+	if err := c.Drain(); err != nil {
+		c.Close()
+		return fmt.Errorf("nats: failed to drain connection: %w", err)
+	}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for !c.IsClosed() {
+		select {
+		case <-ctx.Done():
+			c.Close()
+			return fmt.Errorf("nats: failed to drain connection: %w", ctx.Err())
+
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
 // New returns a pointer to a new instance of Conn.
 func New(addr string) (*Conn, error) {
 	conn, err := nats.Connect(addr)
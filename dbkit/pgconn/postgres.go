@@ -51,6 +51,28 @@ func (c *Conn) Close() error {
 	return nil
 }
 
+// Start checks the connection, which makes Conn the component managed by servekit.Run.
+func (c *Conn) Start(ctx context.Context) error { return c.Health(ctx) }
+
+// Stop closes the connection pool. Waits until all acquired
+// connections are released to the pool or the ctx is done.
+func (c *Conn) Stop(ctx context.Context) error {
+	closed := make(chan struct{})
+
+	go func() {
+		c.Pool.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+		return nil
+
+	case <-ctx.Done():
+		return fmt.Errorf("postgres: close: %w", ctx.Err())
+	}
+}
+
 func (c *Conn) Health(ctx context.Context) error {
 	if err := c.Ping(ctx); err != nil {
 		return fmt.Errorf("postgres: healthcheck failed: %w", err)
@@ -71,3 +71,15 @@ func (c *Conn) HealthCheck(ctx context.Context) error {
 
 	return nil
 }
+
+// Start checks the connection, which makes Conn the component managed by servekit.Run.
+func (c *Conn) Start(ctx context.Context) error { return c.HealthCheck(ctx) }
+
+// Stop closes the client.
+func (c *Conn) Stop(_ context.Context) error {
+	if err := c.Client.Close(); err != nil {
+		return fmt.Errorf("redis: close: %w", err)
+	}
+
+	return nil
+}
@@ -227,3 +227,15 @@ func (c *Conn) Health(ctx context.Context) error {
 
 	return nil
 }
+
+// Start checks the connection, which makes Conn the component managed by servekit.Run.
+func (c *Conn) Start(ctx context.Context) error { return c.Health(ctx) }
+
+// Stop closes the database.
+func (c *Conn) Stop(_ context.Context) error {
+	if err := c.Close(); err != nil {
+		return fmt.Errorf("sqlite: close database: %w", err)
+	}
+
+	return nil
+}
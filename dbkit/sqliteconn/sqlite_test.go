@@ -53,3 +53,22 @@ func TestNew(t *testing.T) {
 		}
 	})
 }
+
+func TestConn_StartStop(t *testing.T) {
+	conn, err := New(path.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := conn.Start(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := conn.Stop(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := conn.Start(context.Background()); err == nil {
+		t.Error("expected error after stop, got nil")
+	}
+}
@@ -27,6 +27,10 @@ const (
 	// This kind of error is retryable. Caller should retry with a backoff.
 	ErrUnavailable Error = "temporarily unavailable"
 
+	// ErrRateLimited indicates that the caller has exceeded the rate limit.
+	// This kind of error is retryable. Caller should retry after the limit resets.
+	ErrRateLimited Error = "rate limit exceeded"
+
 	// ErrConnFailed shows that connection to a resource failed.
 	ErrConnFailed Error = "connection failed"
 
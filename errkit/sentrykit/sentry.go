@@ -92,6 +92,26 @@ func Close() error {
 	return nil
 }
 
-type reporter struct{}
+// reporter reports the errors to the hub, or to the current hub if it is nil.
+// The stack trace carried by the error is attached as the "stack" context.
+type reporter struct {
+	hub *sentry.Hub
+}
+
+func (r reporter) Report(err error) {
+	hub := r.hub
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
 
-func (r reporter) Report(err error) { _ = sentry.CaptureMessage(err.Error()) }
+	stack := errkit.Stack(err)
+	if stack == nil {
+		_ = hub.CaptureMessage(err.Error())
+		return
+	}
+
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetContext("stack", sentry.Context{"trace": string(stack)})
+		_ = hub.CaptureMessage(err.Error())
+	})
+}
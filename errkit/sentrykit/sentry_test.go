@@ -1 +1,78 @@
 package sentrykit
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/heartwilltell/bones/errkit"
+)
+
+// testTransport records the events sent by the client.
+type testTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *testTransport) Flush(time.Duration) bool { return true }
+
+func (t *testTransport) Configure(sentry.ClientOptions) {}
+
+func (t *testTransport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.events = append(t.events, event)
+}
+
+func TestReporter_Report(t *testing.T) {
+	type tcase struct {
+		err       error
+		wantStack sentry.Context
+	}
+
+	tests := map[string]tcase{
+		"NoStack": {err: errors.New("boom"), wantStack: nil},
+		"Stack": {
+			err:       errkit.WithStack(errors.New("boom"), []byte("goroutine 1 [running]:")),
+			wantStack: sentry.Context{"trace": "goroutine 1 [running]:"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var transport testTransport
+
+			client, err := sentry.NewClient(sentry.ClientOptions{Transport: &transport})
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			hub := sentry.NewHub(client, sentry.NewScope())
+
+			reporter{hub: hub}.Report(tc.err)
+
+			// The stack must not leak to the events captured later.
+			hub.CaptureMessage("next")
+
+			if len(transport.events) != 2 {
+				t.Fatalf("got %d events, want 2", len(transport.events))
+			}
+
+			if got := transport.events[0].Message; got != "boom" {
+				t.Errorf("Message = %v, want boom", got)
+			}
+
+			if got := transport.events[0].Contexts["stack"]; !reflect.DeepEqual(got, tc.wantStack) {
+				t.Errorf("Contexts[stack] = %v, want %v", got, tc.wantStack)
+			}
+
+			if got, ok := transport.events[1].Contexts["stack"]; ok {
+				t.Errorf("Contexts[stack] of the next event = %v, want none", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package errkit
+
+import "errors"
+
+// WithStack returns the error which wraps err and carries the stack trace,
+// e.g. the one captured by runtime/debug.Stack when recovering from a panic.
+// The stack trace does not change the error message and can be
+// retrieved by the Stack function, e.g. by the ErrorReporter.
+func WithStack(err error, stack []byte) error {
+	if err == nil {
+		return nil
+	}
+
+	return &stackError{err: err, stack: stack}
+}
+
+// Stack returns the stack trace carried by err or any error it wraps.
+// Returns nil if there is no stack trace.
+func Stack(err error) []byte {
+	var se *stackError
+	if errors.As(err, &se) {
+		return se.stack
+	}
+
+	return nil
+}
+
+// stackError represents the error which carries the stack trace.
+type stackError struct {
+	err   error
+	stack []byte
+}
+
+func (e *stackError) Error() string { return e.err.Error() }
+
+func (e *stackError) Unwrap() error { return e.err }
@@ -0,0 +1,47 @@
+package errkit
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestStack(t *testing.T) {
+	stack := []byte("goroutine 1 [running]:")
+
+	type tcase struct {
+		err       error
+		wantStack []byte
+	}
+
+	tests := map[string]tcase{
+		"Nil":     {err: nil, wantStack: nil},
+		"NoStack": {err: ErrNotFound, wantStack: nil},
+		"Stack":   {err: WithStack(ErrNotFound, stack), wantStack: stack},
+		"Wrapped": {err: fmt.Errorf("failed: %w", WithStack(ErrNotFound, stack)), wantStack: stack},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := Stack(tc.err); string(got) != string(tc.wantStack) {
+				t.Errorf("Stack() = %q, want %q", got, tc.wantStack)
+			}
+		})
+	}
+}
+
+func TestWithStack(t *testing.T) {
+	if err := WithStack(nil, []byte("stack")); err != nil {
+		t.Errorf("WithStack(nil) = %v, want nil", err)
+	}
+
+	err := WithStack(ErrNotFound, []byte("stack"))
+
+	if got := err.Error(); got != ErrNotFound.Error() {
+		t.Errorf("Error() = %v, want %v", got, ErrNotFound.Error())
+	}
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("errors.Is(%v, ErrNotFound) = false, want true", err)
+	}
+}
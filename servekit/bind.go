@@ -0,0 +1,245 @@
+package servekit
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/heartwilltell/bones/errkit"
+)
+
+const (
+	// maxRequestBodySize represents the maximum size of the request body decoded by Bind.
+	maxRequestBodySize = 1 << 20
+
+	// pathTag represents the struct tag which binds the field to the URL path parameter.
+	pathTag = "path"
+
+	// queryTag represents the struct tag which binds the field to the URL query parameter.
+	queryTag = "query"
+
+	// headerTag represents the struct tag which binds the field to the request header.
+	headerTag = "header"
+)
+
+// Validator represents a request which is able to validate itself.
+// Validation errors are reported to the client as errkit.ErrInvalidArgument.
+type Validator interface {
+	Validate() error
+}
+
+// Bind decodes the request into v, which should be a pointer to a struct.
+//
+// The JSON request body (if any) is decoded into v first. Then fields tagged with
+// `path:"name"`, `query:"name"` and `header:"Name"` are set from the URL path parameters,
+// URL query parameters and request headers accordingly. Supported field types are strings,
+// booleans, numbers, time.Duration, time.Time (RFC 3339), encoding.TextUnmarshaler
+// implementations and slices of them. Finally, if v implements Validator, it is validated.
+//
+// All returned errors wrap errkit.ErrInvalidArgument. The error of the body larger than 1 MiB
+// also wraps *http.MaxBytesError, which is responded by HTTP 413 (Request Entity Too Large).
+func Bind(r *http.Request, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: bind target should be a non-nil pointer to a struct, got %T", errkit.ErrInvalidArgument, v)
+	}
+
+	if err := decodeBody(r, v); err != nil {
+		return err
+	}
+
+	if err := bindFields(r, rv.Elem()); err != nil {
+		return err
+	}
+
+	if validator, ok := v.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			if errors.Is(err, errkit.ErrInvalidArgument) {
+				return err
+			}
+
+			return fmt.Errorf("%w: %w", errkit.ErrInvalidArgument, err)
+		}
+	}
+
+	return nil
+}
+
+// decodeBody decodes the JSON request body into v, if the request has one.
+// The body should hold the single JSON value of at most maxRequestBodySize bytes.
+func decodeBody(r *http.Request, v any) error {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxRequestBodySize))
+
+	if err := decoder.Decode(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		return fmt.Errorf("%w: invalid request body: %w", errkit.ErrInvalidArgument, err)
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		if err == nil {
+			err = errors.New("unexpected data after the JSON value")
+		}
+
+		return fmt.Errorf("%w: invalid request body: %w", errkit.ErrInvalidArgument, err)
+	}
+
+	return nil
+}
+
+// bindFields sets the struct fields tagged with path, query or header tags.
+func bindFields(r *http.Request, v reflect.Value) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && value.Kind() == reflect.Struct {
+			if err := bindFields(r, value); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		var values []string
+
+		if name, ok := field.Tag.Lookup(pathTag); ok {
+			if param := chi.URLParam(r, name); param != "" {
+				values = []string{param}
+			}
+		}
+
+		if name, ok := field.Tag.Lookup(queryTag); ok {
+			if query, exist := r.URL.Query()[name]; exist {
+				values = query
+			}
+		}
+
+		if name, ok := field.Tag.Lookup(headerTag); ok {
+			if header := r.Header.Values(name); len(header) > 0 {
+				values = header
+			}
+		}
+
+		if len(values) == 0 {
+			continue
+		}
+
+		if err := setField(value, values); err != nil {
+			return fmt.Errorf("%w: invalid value of %s: %w", errkit.ErrInvalidArgument, field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// setField sets the field value from its string representation.
+func setField(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Slice && !isTextUnmarshaler(field) {
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+
+		for i, value := range values {
+			if err := setValue(slice.Index(i), value); err != nil {
+				return err
+			}
+		}
+
+		field.Set(slice)
+
+		return nil
+	}
+
+	return setValue(field, values[0])
+}
+
+// isTextUnmarshaler reports whether the value implements encoding.TextUnmarshaler.
+func isTextUnmarshaler(v reflect.Value) bool {
+	_, ok := v.Addr().Interface().(encoding.TextUnmarshaler)
+	return ok
+}
+
+// setValue parses the string s and sets the result to v.
+func setValue(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		return setValue(v.Elem(), s)
+	}
+
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(int64(d))
+
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetFloat(f)
+
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
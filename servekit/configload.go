@@ -0,0 +1,669 @@
+package servekit
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"go.uber.org/multierr"
+	"gopkg.in/yaml.v3"
+)
+
+// valueKind represents the kind of configuration value.
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindBool
+	kindInt
+	kindDuration
+	kindFileMode
+	kindClientAuth
+)
+
+// configKeys holds all known configuration keys along with the kinds of their values.
+// Nested keys are separated by dots in files, e.g. 'shutdown: {timeout: 10s}' in YAML,
+// and by underscores in environment variables, e.g. 'APP_SHUTDOWN_TIMEOUT=10s'.
+var configKeys = map[string]valueKind{
+	"addr":                    kindString,
+	"read_timeout":            kindDuration,
+	"write_timeout":           kindDuration,
+	"idle_timeout":            kindDuration,
+	"shutdown.timeout":        kindDuration,
+	"shutdown.drain":          kindDuration,
+	"socket.activation":       kindBool,
+	"socket.activation_name":  kindString,
+	"socket.unix_mode":        kindFileMode,
+	"tls.cert":                kindString,
+	"tls.key":                 kindString,
+	"tls.reload":              kindBool,
+	"tls.reload_interval":     kindDuration,
+	"tls.client_ca":           kindString,
+	"tls.client_auth":         kindClientAuth,
+	"h2c":                     kindBool,
+	"http3.enable":            kindBool,
+	"http3.addr":              kindString,
+	"upgrade.enable":          kindBool,
+	"upgrade.timeout":         kindDuration,
+	"admin.addr":              kindString,
+	"health.enable":           kindBool,
+	"health.route":            kindString,
+	"health.liveness_route":   kindString,
+	"health.readiness_route":  kindString,
+	"health.timeout":          kindDuration,
+	"health.verbose":          kindBool,
+	"health.access_log":       kindBool,
+	"health.metrics":          kindBool,
+	"metrics.enable":          kindBool,
+	"metrics.route":           kindString,
+	"metrics.access_log":      kindBool,
+	"metrics.metrics":         kindBool,
+	"profiler.enable":         kindBool,
+	"profiler.route":          kindString,
+	"profiler.access_log":     kindBool,
+	"profiler.block_rate":     kindInt,
+	"profiler.mutex_fraction": kindInt,
+	"routes.enable":           kindBool,
+	"routes.route":            kindString,
+	"routes.access_log":       kindBool,
+	"openapi.enable":          kindBool,
+	"openapi.route":           kindString,
+	"openapi.title":           kindString,
+	"openapi.version":         kindString,
+	"openapi.description":     kindString,
+	"openapi.access_log":      kindBool,
+}
+
+// clientAuthTypes maps the values of 'tls.client_auth' key to tls.ClientAuthType.
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"request":            tls.RequestClientCert,
+	"require-any":        tls.RequireAnyClientCert,
+	"verify-if-given":    tls.VerifyClientCertIfGiven,
+	"require-and-verify": tls.RequireAndVerifyClientCert,
+}
+
+// ListenerConfig represents the listener configuration loaded by LoadConfig.
+type ListenerConfig struct {
+	// Addr represents the listener address set by the 'addr' key.
+	Addr string
+
+	// CertFile and KeyFile represent the paths of TLS certificate and key
+	// files for ServeTLS set by the 'tls.cert' and 'tls.key' keys.
+	CertFile string
+	KeyFile  string
+
+	// Options holds the listener options built from the configuration.
+	Options []ListenerOption
+}
+
+// New returns a new instance of ListenerHTTP configured by the loaded configuration.
+// The given options are applied first, so the loaded configuration overrides them.
+func (c *ListenerConfig) New(options ...ListenerOption) (*ListenerHTTP, error) {
+	all := make([]ListenerOption, 0, len(options)+len(c.Options))
+	all = append(all, options...)
+	all = append(all, c.Options...)
+
+	return New(c.Addr, all...)
+}
+
+// LoaderConfig represents configuration of the LoadConfig.
+type LoaderConfig struct {
+	file      string
+	envPrefix string
+	env       bool
+}
+
+// ConfigFile represents an optional function for LoadConfig function.
+// If passed to the LoadConfig, will load the configuration from the file by the given path.
+// The format of the file is chosen by its extension: '.yaml', '.yml', '.json' or '.toml'.
+func ConfigFile(path string) Option[*LoaderConfig] {
+	return func(c *LoaderConfig) { c.file = path }
+}
+
+// ConfigEnv represents an optional function for LoadConfig function.
+// If passed to the LoadConfig, will load the configuration from the environment variables
+// with the given prefix, e.g. 'APP_SHUTDOWN_TIMEOUT' for the 'APP' prefix.
+// Environment variables take precedence over the file.
+//
+// Since the unknown variables with the prefix are reported as errors,
+// use the prefix dedicated to the listener, e.g. 'APP_HTTP'.
+func ConfigEnv(prefix string) Option[*LoaderConfig] {
+	return func(c *LoaderConfig) {
+		c.env = true
+		c.envPrefix = prefix
+	}
+}
+
+// LoadConfig loads the listener configuration from the file and/or environment
+// variables, and builds the listener options from it by the corresponding option
+// functions, e.g. 'shutdown.timeout' key is applied by WithShutdownTimeout.
+//
+// Values of the keys are validated by their types, and the unknown keys are reported
+// as errors, so typos do not go unnoticed. The 'socket.unix_mode' key should be an octal
+// string, e.g. '0660', and the 'tls.client_auth' key requires the 'tls.client_ca' key.
+// The rest of validation happens in New.
+//
+// Example of the YAML file:
+//
+//	addr: ":8080"
+//	read_timeout: 10s
+//	shutdown:
+//	  timeout: 30s
+//	  drain: 5s
+//	admin:
+//	  addr: ":9090"
+//	health:
+//	  enable: true
+//	metrics:
+//	  enable: true
+func LoadConfig(options ...Option[*LoaderConfig]) (*ListenerConfig, error) {
+	cfg := LoaderConfig{}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	values := make(configValues)
+
+	if cfg.file != "" {
+		if err := values.loadFile(cfg.file); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.env {
+		values.loadEnv(cfg.envPrefix, os.Environ())
+	}
+
+	if err := values.parse(); err != nil {
+		return nil, err
+	}
+
+	return values.build(), nil
+}
+
+// configValue represents the configuration value along with its source.
+type configValue struct {
+	value  any
+	source string
+}
+
+// configValues holds the configuration values by their keys.
+type configValues map[string]configValue
+
+// loadFile loads the values from the file.
+func (v configValues) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration file: %w", err)
+	}
+
+	raw := make(map[string]any)
+
+	switch ext := filepath.Ext(path); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".json":
+		err = json.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return fmt.Errorf("unsupported configuration file format: %q", ext)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to decode configuration file %s: %w", path, err)
+	}
+
+	v.flatten("", raw, path)
+
+	return nil
+}
+
+// flatten adds the values of the nested maps by the keys joined by dots.
+func (v configValues) flatten(prefix string, raw map[string]any, source string) {
+	for key, value := range raw {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if nested, ok := value.(map[string]any); ok {
+			v.flatten(key, nested, source)
+			continue
+		}
+
+		v[key] = configValue{value: value, source: source}
+	}
+}
+
+// loadEnv loads the values from the environment variables with the given prefix.
+// The variable names are matched to the known keys, e.g. 'APP_SHUTDOWN_TIMEOUT' to 'shutdown.timeout'.
+func (v configValues) loadEnv(prefix string, environ []string) {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+
+	names := make(map[string]string, len(configKeys))
+	for key := range configKeys {
+		names[prefix+envName(key)] = key
+	}
+
+	for _, env := range environ {
+		name, value, _ := strings.Cut(env, "=")
+
+		if key, ok := names[name]; ok {
+			v[key] = configValue{value: value, source: "environment variable " + name}
+			continue
+		}
+
+		// Report the unknown variables only if they are surely meant for
+		// the listener, which is impossible to tell without the prefix.
+		if prefix != "" && strings.HasPrefix(name, prefix) {
+			v[name] = configValue{value: value, source: "environment variable " + name}
+		}
+	}
+}
+
+// envName returns the name of the environment variable of the key without prefix.
+func envName(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// parse converts the values to the types of their keys and reports the unknown keys.
+func (v configValues) parse() error {
+	keys := make([]string, 0, len(v))
+	for key := range v {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var err error
+
+	for _, key := range keys {
+		value := v[key]
+
+		kind, ok := configKeys[key]
+		if !ok {
+			multierr.AppendInto(&err, fmt.Errorf("unknown configuration key %q in %s", key, value.source))
+			continue
+		}
+
+		parsed, parseErr := parseValue(kind, value.value)
+		if parseErr != nil {
+			multierr.AppendInto(&err, fmt.Errorf("invalid value of configuration key %q in %s: %w", key, value.source, parseErr))
+			continue
+		}
+
+		v[key] = configValue{value: parsed, source: value.source}
+	}
+
+	if clientAuth, ok := v["tls.client_auth"]; ok {
+		if _, ok := v["tls.client_ca"]; !ok {
+			multierr.AppendInto(&err, fmt.Errorf("invalid configuration in %s: tls.client_auth requires tls.client_ca", clientAuth.source))
+		}
+	}
+
+	return err
+}
+
+// parseValue converts the value to the type of the given kind.
+func parseValue(kind valueKind, value any) (any, error) {
+	s, isString := value.(string)
+
+	switch kind {
+	case kindString:
+		switch v := value.(type) {
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+
+		case string, bool, int, int64, uint64:
+			return fmt.Sprint(v), nil
+		}
+
+	case kindBool:
+		if isString {
+			return strconv.ParseBool(s)
+		}
+
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+
+	case kindInt:
+		if isString {
+			return strconv.Atoi(s)
+		}
+
+		return toInt(value)
+
+	case kindDuration:
+		if isString {
+			return time.ParseDuration(s)
+		}
+
+		return nil, errors.New("should be a duration string, e.g. '5s'")
+
+	case kindFileMode:
+		// Numbers are rejected, since they are decimal in JSON and most of YAML
+		// decoders, so 660 would silently become 01224 instead of 0660.
+		if isString {
+			mode, err := strconv.ParseUint(s, 8, 32)
+			return fs.FileMode(mode), err
+		}
+
+		return nil, errors.New("should be an octal string, e.g. '0660'")
+
+	case kindClientAuth:
+		if clientAuth, ok := clientAuthTypes[s]; ok {
+			return clientAuth, nil
+		}
+
+		return nil, fmt.Errorf("should be one of: request, require-any, verify-if-given, require-and-verify")
+	}
+
+	return nil, fmt.Errorf("unsupported value type %T", value)
+}
+
+// toInt converts the decoded number to int.
+func toInt(value any) (int, error) {
+	switch n := value.(type) {
+	case int:
+		return n, nil
+
+	case int64:
+		return int(n), nil
+
+	case uint64:
+		return int(n), nil
+
+	case float64:
+		if n != math.Trunc(n) {
+			return 0, fmt.Errorf("should be an integer, got %v", n)
+		}
+
+		return int(n), nil
+
+	default:
+		return 0, fmt.Errorf("should be an integer, got %T", value)
+	}
+}
+
+// build builds the listener configuration from the parsed values.
+func (v configValues) build() *ListenerConfig {
+	cfg := ListenerConfig{Options: make([]ListenerOption, 0)}
+
+	add := func(opt ListenerOption) { cfg.Options = append(cfg.Options, opt) }
+
+	if addr, ok := lookup[string](v, "addr"); ok {
+		cfg.Addr = addr
+	}
+
+	if d, ok := lookup[time.Duration](v, "read_timeout"); ok {
+		add(WithReadTimeout(d))
+	}
+
+	if d, ok := lookup[time.Duration](v, "write_timeout"); ok {
+		add(WithWriteTimeout(d))
+	}
+
+	if d, ok := lookup[time.Duration](v, "idle_timeout"); ok {
+		add(WithIdleTimeout(d))
+	}
+
+	if d, ok := lookup[time.Duration](v, "shutdown.timeout"); ok {
+		add(WithShutdownTimeout(d))
+	}
+
+	if d, ok := lookup[time.Duration](v, "shutdown.drain"); ok {
+		add(WithShutdownDrain(d))
+	}
+
+	if enable, ok := lookup[bool](v, "socket.activation"); ok {
+		if !enable {
+			add(func(c *config) { c.socket.activation = false })
+		} else {
+			name, _ := lookup[string](v, "socket.activation_name")
+			add(WithSocketActivation(name))
+		}
+	}
+
+	if mode, ok := lookup[fs.FileMode](v, "socket.unix_mode"); ok {
+		add(WithUnixSocketMode(mode))
+	}
+
+	cfg.CertFile, _ = lookup[string](v, "tls.cert")
+	cfg.KeyFile, _ = lookup[string](v, "tls.key")
+
+	if disabled(v, "tls.reload") {
+		add(func(c *config) { c.tlsReload.enable = false })
+	} else if interval, ok := lookup[time.Duration](v, "tls.reload_interval"); enabled(v, "tls.reload", ok) {
+		var options []Option[*TLSReloadConfig]
+		if ok {
+			options = append(options, TLSReloadInterval(interval))
+		}
+
+		add(WithTLSReload(options...))
+	}
+
+	if caFile, ok := lookup[string](v, "tls.client_ca"); ok {
+		var options []Option[*MutualTLSConfig]
+		if clientAuth, ok := lookup[tls.ClientAuthType](v, "tls.client_auth"); ok {
+			options = append(options, MutualTLSClientAuth(clientAuth))
+		}
+
+		add(WithMutualTLS(caFile, options...))
+	}
+
+	if h2c, ok := lookup[bool](v, "h2c"); ok {
+		add(func(c *config) { c.h2c = h2c })
+	}
+
+	if disabled(v, "http3.enable") {
+		add(func(c *config) { c.http3.enable = false })
+	} else if addr, ok := lookup[string](v, "http3.addr"); enabled(v, "http3.enable", ok) {
+		var options []Option[*HTTP3Config]
+		if ok {
+			options = append(options, HTTP3Addr(addr))
+		}
+
+		add(WithHTTP3(options...))
+	}
+
+	if disabled(v, "upgrade.enable") {
+		add(func(c *config) { c.upgrade.enable = false })
+	} else if timeout, ok := lookup[time.Duration](v, "upgrade.timeout"); enabled(v, "upgrade.enable", ok) {
+		var options []Option[*UpgradeConfig]
+		if ok {
+			options = append(options, UpgradeTimeout(timeout))
+		}
+
+		add(WithUpgrade(options...))
+	}
+
+	if addr, ok := lookup[string](v, "admin.addr"); ok {
+		add(WithAdminListener(addr))
+	}
+
+	if disabled(v, "health.enable") {
+		add(func(c *config) { c.health.enable = false })
+	} else if options := v.healthOptions(); enabled(v, "health.enable", len(options) > 0) {
+		add(WithHealthCheck(options...))
+	}
+
+	if disabled(v, "metrics.enable") {
+		add(func(c *config) { c.metrics.enable = false })
+	} else if options := v.metricsOptions(); enabled(v, "metrics.enable", len(options) > 0) {
+		add(WithMetrics(options...))
+	}
+
+	if disabled(v, "profiler.enable") {
+		add(func(c *config) { c.profiler.enable = false })
+	} else if options := v.profilerOptions(); enabled(v, "profiler.enable", len(options) > 0) {
+		add(WithProfiler(options...))
+	}
+
+	if disabled(v, "routes.enable") {
+		add(func(c *config) { c.routes.enable = false })
+	} else if options := v.routesOptions(); enabled(v, "routes.enable", len(options) > 0) {
+		add(WithRoutes(options...))
+	}
+
+	title, hasTitle := lookup[string](v, "openapi.title")
+	version, hasVersion := lookup[string](v, "openapi.version")
+
+	if disabled(v, "openapi.enable") {
+		add(func(c *config) { c.openAPI.enable = false })
+	} else if options := v.openAPIOptions(); enabled(v, "openapi.enable", len(options) > 0 || hasTitle || hasVersion) {
+		if !hasTitle {
+			title = "API"
+		}
+
+		if !hasVersion {
+			version = "0.0.0"
+		}
+
+		add(WithOpenAPI(title, version, options...))
+	}
+
+	return &cfg
+}
+
+func (v configValues) healthOptions() []Option[*HealthEndpointConfig] {
+	options := make([]Option[*HealthEndpointConfig], 0)
+
+	if route, ok := lookup[string](v, "health.route"); ok {
+		options = append(options, HealthCheckRoute(route))
+	}
+
+	if route, ok := lookup[string](v, "health.liveness_route"); ok {
+		options = append(options, HealthCheckLivenessRoute(route))
+	}
+
+	if route, ok := lookup[string](v, "health.readiness_route"); ok {
+		options = append(options, HealthCheckReadinessRoute(route))
+	}
+
+	if timeout, ok := lookup[time.Duration](v, "health.timeout"); ok {
+		options = append(options, HealthCheckTimeout(timeout))
+	}
+
+	if verbose, ok := lookup[bool](v, "health.verbose"); ok {
+		options = append(options, HealthCheckVerbose(verbose))
+	}
+
+	if accessLog, ok := lookup[bool](v, "health.access_log"); ok {
+		options = append(options, HealthCheckAccessLog(accessLog))
+	}
+
+	if metrics, ok := lookup[bool](v, "health.metrics"); ok {
+		options = append(options, HealthCheckMetricsForEndpoint(metrics))
+	}
+
+	return options
+}
+
+func (v configValues) metricsOptions() []Option[*MetricsEndpointConfig] {
+	options := make([]Option[*MetricsEndpointConfig], 0)
+
+	if route, ok := lookup[string](v, "metrics.route"); ok {
+		options = append(options, MetricsRoute(route))
+	}
+
+	if accessLog, ok := lookup[bool](v, "metrics.access_log"); ok {
+		options = append(options, MetricsAccessLog(accessLog))
+	}
+
+	if metrics, ok := lookup[bool](v, "metrics.metrics"); ok {
+		options = append(options, MetricsMetricsForEndpoint(metrics))
+	}
+
+	return options
+}
+
+func (v configValues) profilerOptions() []Option[*ProfilerEndpointConfig] {
+	options := make([]Option[*ProfilerEndpointConfig], 0)
+
+	if route, ok := lookup[string](v, "profiler.route"); ok {
+		options = append(options, ProfilerRoute(route))
+	}
+
+	if accessLog, ok := lookup[bool](v, "profiler.access_log"); ok {
+		options = append(options, ProfilerAccessLog(accessLog))
+	}
+
+	if rate, ok := lookup[int](v, "profiler.block_rate"); ok {
+		options = append(options, ProfilerBlockRate(rate))
+	}
+
+	if fraction, ok := lookup[int](v, "profiler.mutex_fraction"); ok {
+		options = append(options, ProfilerMutexFraction(fraction))
+	}
+
+	return options
+}
+
+func (v configValues) routesOptions() []Option[*RoutesEndpointConfig] {
+	options := make([]Option[*RoutesEndpointConfig], 0)
+
+	if route, ok := lookup[string](v, "routes.route"); ok {
+		options = append(options, RoutesRoute(route))
+	}
+
+	if accessLog, ok := lookup[bool](v, "routes.access_log"); ok {
+		options = append(options, RoutesAccessLog(accessLog))
+	}
+
+	return options
+}
+
+func (v configValues) openAPIOptions() []Option[*OpenAPIEndpointConfig] {
+	options := make([]Option[*OpenAPIEndpointConfig], 0)
+
+	if route, ok := lookup[string](v, "openapi.route"); ok {
+		options = append(options, OpenAPIRoute(route))
+	}
+
+	if description, ok := lookup[string](v, "openapi.description"); ok {
+		options = append(options, OpenAPIDescription(description))
+	}
+
+	if accessLog, ok := lookup[bool](v, "openapi.access_log"); ok {
+		options = append(options, OpenAPIAccessLog(accessLog))
+	}
+
+	return options
+}
+
+// enabled reports whether the feature is enabled by its enable key.
+// If the key is not set, the feature is enabled if any of its keys is set.
+func enabled(v configValues, key string, configured bool) bool {
+	if enable, ok := lookup[bool](v, key); ok {
+		return enable
+	}
+
+	return configured
+}
+
+// disabled reports whether the feature is explicitly disabled by its enable key,
+// which overrides the option enabling it passed to ListenerConfig.New.
+func disabled(v configValues, key string) bool {
+	enable, ok := lookup[bool](v, key)
+	return ok && !enable
+}
+
+// lookup returns the parsed value of the key.
+func lookup[T any](v configValues, key string) (T, bool) {
+	value, ok := v[key].value.(T)
+	return value, ok
+}
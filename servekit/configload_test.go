@@ -0,0 +1,184 @@
+package servekit
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestLoadConfig(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": `
+addr: ":8080"
+read_timeout: 10s
+shutdown:
+  timeout: 30s
+  drain: 1s
+tls:
+  cert: /etc/tls/tls.crt
+  key: /etc/tls/tls.key
+admin:
+  addr: ":9090"
+health:
+  verbose: true
+  readiness_route: /ready
+metrics:
+  enable: false
+  route: /stats
+openapi:
+  title: Items
+  version: "1.0"
+socket:
+  unix_mode: "0660"
+`,
+		"config.json": `{
+	"addr": ":8080",
+	"read_timeout": "10s",
+	"shutdown": {"timeout": "30s", "drain": "1s"},
+	"tls": {"cert": "/etc/tls/tls.crt", "key": "/etc/tls/tls.key"},
+	"admin": {"addr": ":9090"},
+	"health": {"verbose": true, "readiness_route": "/ready"},
+	"metrics": {"enable": false, "route": "/stats"},
+	"openapi": {"title": "Items", "version": "1.0"},
+	"socket": {"unix_mode": "0660"}
+}`,
+		"config.toml": `
+addr = ":8080"
+read_timeout = "10s"
+
+[shutdown]
+timeout = "30s"
+drain = "1s"
+
+[tls]
+cert = "/etc/tls/tls.crt"
+key = "/etc/tls/tls.key"
+
+[admin]
+addr = ":9090"
+
+[health]
+verbose = true
+readiness_route = "/ready"
+
+[metrics]
+enable = false
+route = "/stats"
+
+[openapi]
+title = "Items"
+version = "1.0"
+
+[socket]
+unix_mode = "660"
+`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			td.Require(t).CmpNoError(os.WriteFile(path, []byte(content), 0o600))
+
+			t.Setenv("TEST_HTTP_SHUTDOWN_TIMEOUT", "45s")
+			t.Setenv("TEST_HTTP_WRITE_TIMEOUT", "20s")
+
+			cfg, err := LoadConfig(ConfigFile(path), ConfigEnv("TEST_HTTP"))
+			td.Require(t).CmpNoError(err)
+
+			td.Cmp(t, cfg.Addr, ":8080")
+			td.Cmp(t, cfg.CertFile, "/etc/tls/tls.crt")
+			td.Cmp(t, cfg.KeyFile, "/etc/tls/tls.key")
+
+			l, err := cfg.New(WithShutdownTimeout(time.Second), WithMetrics())
+			td.Require(t).CmpNoError(err)
+
+			td.Cmp(t, l.server.Addr, ":8080")
+			td.Cmp(t, l.server.ReadTimeout, 10*time.Second)
+			td.Cmp(t, l.server.WriteTimeout, 20*time.Second)
+			td.Cmp(t, l.shutdown.timeout, 45*time.Second)
+			td.Cmp(t, l.shutdown.drain, time.Second)
+			td.Cmp(t, l.socket.unixMode, os.FileMode(0o660))
+			td.Cmp(t, l.admin.Addr, ":9090")
+			td.Cmp(t, l.readiness.verbose, true)
+			td.Cmp(t, l.openAPI, td.Struct(OpenAPIEndpointConfig{title: "Items", version: "1.0", enable: true}, nil))
+
+			routes, err := l.Routes()
+			td.Require(t).CmpNoError(err)
+			td.Cmp(t, routes, td.SuperBagOf(
+				td.Struct(Route{Listener: adminListenerName, Pattern: "/ready"}, nil),
+				td.Struct(Route{Listener: adminListenerName, Pattern: "/livez"}, nil),
+			))
+
+			// The metrics endpoint enabled by the code option is disabled by the configuration.
+			td.Cmp(t, routes, td.Not(td.Contains(td.Struct(Route{Pattern: "/metrics"}, nil))))
+		})
+	}
+
+	t.Run("MutualTLS", func(t *testing.T) {
+		t.Setenv("TEST_TLS_TLS_CLIENT_CA", "/etc/tls/ca.crt")
+		t.Setenv("TEST_TLS_TLS_CLIENT_AUTH", "request")
+
+		cfg, err := LoadConfig(ConfigEnv("TEST_TLS"))
+		td.Require(t).CmpNoError(err)
+
+		var c config
+		for _, opt := range cfg.Options {
+			opt(&c)
+		}
+
+		td.Cmp(t, c.mutualTLS, MutualTLSConfig{caFile: "/etc/tls/ca.crt", clientAuth: tls.RequestClientCert, enable: true})
+	})
+
+	t.Run("Upgrade", func(t *testing.T) {
+		t.Setenv("TEST_UPGRADE_UPGRADE_TIMEOUT", "1m")
+
+		cfg, err := LoadConfig(ConfigEnv("TEST_UPGRADE"))
+		td.Require(t).CmpNoError(err)
+
+		var c config
+		for _, opt := range cfg.Options {
+			opt(&c)
+		}
+
+		td.Cmp(t, c.upgrade, UpgradeConfig{timeout: time.Minute, enable: true})
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		td.Require(t).CmpNoError(os.WriteFile(path, []byte(`
+read_timout: 10s
+shutdown:
+  timeout: 30
+health:
+  enable: maybe
+socket:
+  unix_mode: 660
+tls:
+  client_auth: always
+`), 0o600))
+
+		t.Setenv("TEST_BAD_ADMIN_ADDRESS", ":9090")
+
+		_, err := LoadConfig(ConfigFile(path), ConfigEnv("TEST_BAD"))
+		td.CmpString(t, err, `unknown configuration key "TEST_BAD_ADMIN_ADDRESS" in environment variable TEST_BAD_ADMIN_ADDRESS; `+
+			`invalid value of configuration key "health.enable" in `+path+`: strconv.ParseBool: parsing "maybe": invalid syntax; `+
+			`unknown configuration key "read_timout" in `+path+`; `+
+			`invalid value of configuration key "shutdown.timeout" in `+path+`: should be a duration string, e.g. '5s'; `+
+			`invalid value of configuration key "socket.unix_mode" in `+path+`: should be an octal string, e.g. '0660'; `+
+			`invalid value of configuration key "tls.client_auth" in `+path+`: should be one of: request, require-any, verify-if-given, require-and-verify; `+
+			`invalid configuration in `+path+`: tls.client_auth requires tls.client_ca`,
+		)
+	})
+
+	t.Run("UnsupportedFormat", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.ini")
+		td.Require(t).CmpNoError(os.WriteFile(path, nil, 0o600))
+
+		_, err := LoadConfig(ConfigFile(path))
+		td.CmpString(t, err, `unsupported configuration file format: ".ini"`)
+	})
+}
@@ -0,0 +1,27 @@
+package servekit
+
+// This is compiling time check for interface implementation.
+var _ error = (Error)("")
+
+const (
+	// ErrBindFailed indicates that the listener was unable
+	// to bind to the configured network address.
+	ErrBindFailed Error = "servekit: failed to bind listener"
+
+	// ErrServeFailed indicates that the listener has been bound
+	// but failed while accepting or serving connections.
+	ErrServeFailed Error = "servekit: listener failed"
+
+	// ErrShutdownTimeout indicates that the listener did not manage to
+	// drain active connections within the shutdown timeout.
+	ErrShutdownTimeout Error = "servekit: graceful shutdown timed out"
+
+	// ErrShutdownFailed indicates that the listener failed
+	// to shut down for reason other than timeout.
+	ErrShutdownFailed Error = "servekit: graceful shutdown failed"
+)
+
+// Error type represents package level errors.
+type Error string
+
+func (e Error) Error() string { return string(e) }
@@ -0,0 +1,78 @@
+package servekit
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/heartwilltell/bones/servekit/respond"
+)
+
+// HandlerFunc represents a typed handler function which receives
+// the decoded request and returns the response to be encoded.
+type HandlerFunc[Req, Resp any] func(ctx context.Context, req Req) (Resp, error)
+
+// StatusCoder represents a response which defines its own HTTP status code.
+// Responses which do not implement it are sent with HTTP 200 (OK) status.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// Handle registers the typed handler fn for the given method and pattern on the router.
+//
+// The request is decoded into Req by Bind, so Req should be a struct. Errors returned
+// by Bind and fn are responded by respond.Error, which maps errkit errors to HTTP status
+// codes. The response is encoded by respond.JSON, unless its status is HTTP 204 (No Content).
+//
+// The optional RouteDoc options describe the route in the document returned by ListenerHTTP.OpenAPI.
+// Request and response types are described automatically.
+//
+// Example:
+//
+//	servekit.Handle(router, http.MethodGet, "/users/{id}", func(ctx context.Context, req GetUserRequest) (User, error) {
+//		return users.Get(ctx, req.ID)
+//	}, servekit.DocSummary("Get the user"), servekit.DocErrors(errkit.ErrNotFound))
+func Handle[Req, Resp any](router chi.Router, method, pattern string, fn HandlerFunc[Req, Resp], options ...Option[*RouteDoc]) {
+	router.Method(method, pattern, TypedHandler(fn, options...))
+}
+
+// TypedHandler returns the http.Handler which serves the typed handler fn.
+// See Handle for the details.
+func TypedHandler[Req, Resp any](fn HandlerFunc[Req, Resp], options ...Option[*RouteDoc]) http.Handler {
+	return &typedHandler[Req, Resp]{fn: fn, doc: typedRouteDoc[Req, Resp](options...)}
+}
+
+// typedHandler implements http.Handler for the typed handler function.
+type typedHandler[Req, Resp any] struct {
+	fn  HandlerFunc[Req, Resp]
+	doc *RouteDoc
+}
+
+func (h *typedHandler[Req, Resp]) routeDoc() *RouteDoc { return h.doc }
+
+func (h *typedHandler[Req, Resp]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req Req
+
+	if err := Bind(r, &req); err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	resp, err := h.fn(r.Context(), req)
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	if coder, ok := any(resp).(StatusCoder); ok {
+		status = coder.StatusCode()
+	}
+
+	if status == http.StatusNoContent {
+		respond.Status(w, r, status)
+		return
+	}
+
+	respond.JSON(w, r, status, resp)
+}
@@ -0,0 +1,164 @@
+package servekit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/heartwilltell/bones/errkit"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+type testRequest struct {
+	ID      int           `path:"id"`
+	Tags    []string      `query:"tag"`
+	Timeout time.Duration `query:"timeout"`
+	Token   string        `header:"X-Token"`
+	Name    string        `json:"name"`
+}
+
+func (r testRequest) Validate() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+
+	return nil
+}
+
+type testResponse struct {
+	ID      int      `json:"id"`
+	Tags    []string `json:"tags"`
+	Timeout string   `json:"timeout"`
+	Token   string   `json:"token"`
+	Name    string   `json:"name"`
+}
+
+type testCreated struct{}
+
+func (testCreated) StatusCode() int { return http.StatusNoContent }
+
+func TestHandle(t *testing.T) {
+	router := chi.NewRouter()
+
+	Handle(router, http.MethodPut, "/items/{id}", func(_ context.Context, req testRequest) (testResponse, error) {
+		if req.ID == 404 {
+			return testResponse{}, errkit.ErrNotFound
+		}
+
+		return testResponse{
+			ID:      req.ID,
+			Tags:    req.Tags,
+			Timeout: req.Timeout.String(),
+			Token:   req.Token,
+			Name:    req.Name,
+		}, nil
+	})
+
+	Handle(router, http.MethodDelete, "/items/{id}", func(context.Context, struct{}) (testCreated, error) {
+		return testCreated{}, nil
+	})
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		r.Header.Set("X-Token", "secret")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		return w
+	}
+
+	type tcase struct {
+		method, target, body string
+		wantStatus           int
+		wantBody             td.TestDeep
+	}
+
+	tests := map[string]tcase{
+		"OK": {
+			method:     http.MethodPut,
+			target:     "/items/42?tag=a&tag=b&timeout=1s",
+			body:       `{"name": "test"}`,
+			wantStatus: http.StatusOK,
+			wantBody:   td.JSON(`{"id": 42, "tags": ["a", "b"], "timeout": "1s", "token": "secret", "name": "test"}`),
+		},
+		"NoContent": {
+			method:     http.MethodDelete,
+			target:     "/items/42",
+			wantStatus: http.StatusNoContent,
+		},
+		"InvalidPath": {
+			method:     http.MethodPut,
+			target:     "/items/abc",
+			body:       `{"name": "test"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"InvalidQuery": {
+			method:     http.MethodPut,
+			target:     "/items/42?timeout=forever",
+			body:       `{"name": "test"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"InvalidBody": {
+			method:     http.MethodPut,
+			target:     "/items/42",
+			body:       `{"name":`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"TrailingData": {
+			method:     http.MethodPut,
+			target:     "/items/42",
+			body:       `{"name": "test"} garbage`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"BodyTooLarge": {
+			method:     http.MethodPut,
+			target:     "/items/42",
+			body:       `{"name": "` + strings.Repeat("a", maxRequestBodySize) + `"}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		"ValidationFailed": {
+			method:     http.MethodPut,
+			target:     "/items/42",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		"HandlerError": {
+			method:     http.MethodPut,
+			target:     "/items/404",
+			body:       `{"name": "test"}`,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := do(tc.method, tc.target, tc.body)
+			td.Cmp(t, w.Code, tc.wantStatus)
+
+			if tc.wantBody != nil {
+				td.Cmp(t, w.Body.Bytes(), td.Smuggle(json.RawMessage(nil), tc.wantBody))
+			}
+		})
+	}
+}
+
+func TestBind(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	td.Cmp(t, Bind(r, testRequest{}), td.ErrorIs(errkit.ErrInvalidArgument))
+	td.Cmp(t, Bind(r, (*testRequest)(nil)), td.ErrorIs(errkit.ErrInvalidArgument))
+
+	var unsupported struct {
+		Value map[string]string `query:"value"`
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/?value=1", nil)
+	td.Cmp(t, Bind(r, &unsupported), td.ErrorIs(errkit.ErrInvalidArgument))
+}
@@ -0,0 +1,192 @@
+package servekit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/heartwilltell/bones/errkit"
+	"github.com/heartwilltell/bones/servekit/respond"
+	"github.com/heartwilltell/hc"
+)
+
+const (
+	// healthStatusOK represents the status of passed health check.
+	healthStatusOK = "ok"
+
+	// healthStatusFail represents the status of failed health check.
+	healthStatusFail = "fail"
+
+	// verboseQueryParam represents the query parameter which turns on the verbose health report.
+	verboseQueryParam = "verbose"
+)
+
+// This is compiling time check for interface implementation.
+var _ hc.HealthChecker = (HealthCheckFunc)(nil)
+
+// HealthCheckFunc represents an adapter to allow the use of ordinary functions
+// as hc.HealthChecker, e.g. HealthCheckFunc(redisConn.HealthCheck).
+type HealthCheckFunc func(ctx context.Context) error
+
+// Health calls f(ctx).
+func (f HealthCheckFunc) Health(ctx context.Context) error { return f(ctx) }
+
+// HealthReport represents the verbose report of liveness and readiness endpoints.
+type HealthReport struct {
+	// Status represents the overall status.
+	Status string `json:"status"`
+
+	// Ready represents the state of the readiness gate.
+	// Not ready until the listener starts serving and since the shutdown begins.
+	Ready bool `json:"ready"`
+
+	// Checks holds the status of each dependency.
+	Checks []DependencyReport `json:"checks,omitempty"`
+}
+
+// DependencyReport represents the health status of a single dependency.
+type DependencyReport struct {
+	// Name represents the dependency name.
+	Name string `json:"name"`
+
+	// Status represents the dependency status.
+	Status string `json:"status"`
+
+	// Latency represents the duration of the dependency health check.
+	Latency string `json:"latency"`
+
+	// Error represents the error returned by the current health check.
+	Error string `json:"error,omitempty"`
+
+	// LastError represents the last error returned by the health check.
+	LastError string `json:"lastError,omitempty"`
+
+	// LastErrorAt represents the time the last error occurred at.
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty"`
+}
+
+// dependency represents a named health checker which remembers the last error.
+type dependency struct {
+	name    string
+	checker hc.HealthChecker
+
+	mu          sync.Mutex
+	lastError   error
+	lastErrorAt time.Time
+}
+
+// check runs the health check and reports the dependency status.
+func (d *dependency) check(ctx context.Context) DependencyReport {
+	start := time.Now()
+	err := d.checker.Health(ctx)
+	report := DependencyReport{
+		Name:    d.name,
+		Status:  healthStatusOK,
+		Latency: time.Since(start).String(),
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err != nil {
+		d.lastError, d.lastErrorAt = err, start.UTC()
+		report.Status, report.Error = healthStatusFail, err.Error()
+	}
+
+	if d.lastError != nil {
+		lastErrorAt := d.lastErrorAt
+		report.LastError, report.LastErrorAt = d.lastError.Error(), &lastErrorAt
+	}
+
+	return report
+}
+
+// readiness holds the readiness gate and the named dependencies checked by the readiness endpoint.
+type readiness struct {
+	ready        atomic.Bool
+	dependencies []*dependency
+	timeout      time.Duration
+	verbose      bool
+}
+
+// check runs all dependencies health checks concurrently.
+func (rd *readiness) check(ctx context.Context) HealthReport {
+	report := HealthReport{
+		Status: healthStatusOK,
+		Ready:  rd.ready.Load(),
+		Checks: make([]DependencyReport, len(rd.dependencies)),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, rd.timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+
+	for i, dep := range rd.dependencies {
+		wg.Add(1)
+
+		go func(i int, dep *dependency) {
+			defer wg.Done()
+			report.Checks[i] = dep.check(ctx)
+		}(i, dep)
+	}
+
+	wg.Wait()
+
+	if !report.Ready {
+		report.Status = healthStatusFail
+	}
+
+	for _, c := range report.Checks {
+		if c.Status != healthStatusOK {
+			report.Status = healthStatusFail
+		}
+	}
+
+	return report
+}
+
+// isVerbose reports whether the verbose report is requested.
+func (rd *readiness) isVerbose(r *http.Request) bool {
+	if v, ok := r.URL.Query()[verboseQueryParam]; ok {
+		return len(v) == 0 || v[0] != "false" && v[0] != "0"
+	}
+
+	return rd.verbose
+}
+
+// liveness responds with OK status while the process is able to serve requests.
+func (l *ListenerHTTP) liveness(w http.ResponseWriter, r *http.Request) {
+	if !l.readiness.isVerbose(r) {
+		respond.Status(w, r, http.StatusOK)
+		return
+	}
+
+	respond.JSON(w, r, http.StatusOK, HealthReport{Status: healthStatusOK, Ready: l.readiness.ready.Load()})
+}
+
+// readinessCheck responds with OK status when the readiness gate is open,
+// and all the dependencies are healthy, otherwise responds with errkit.ErrUnavailable.
+func (l *ListenerHTTP) readinessCheck(w http.ResponseWriter, r *http.Request) {
+	report := l.readiness.check(r.Context())
+
+	status := http.StatusOK
+	if report.Status != healthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	if l.readiness.isVerbose(r) {
+		respond.JSON(w, r, status, report)
+		return
+	}
+
+	if status != http.StatusOK {
+		respond.Error(w, r, fmt.Errorf("%w: not ready", errkit.ErrUnavailable))
+		return
+	}
+
+	respond.Status(w, r, http.StatusOK)
+}
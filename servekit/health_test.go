@@ -0,0 +1,79 @@
+package servekit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestHealthCheckFunc_Health(t *testing.T) {
+	errTest := errors.New("test")
+
+	td.Cmp(t, HealthCheckFunc(func(context.Context) error { return errTest }).Health(context.Background()), errTest)
+}
+
+func TestListenerHTTP_readiness(t *testing.T) {
+	var dbErr error
+
+	l, err := New(":0", WithHealthCheck(
+		HealthCheckDependency("postgres", HealthCheckFunc(func(context.Context) error { return dbErr })),
+		HealthCheckDependency("redis", HealthCheckFunc(func(context.Context) error { return nil })),
+	))
+	td.Require(t).CmpNoError(err)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		l.server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+		return w
+	}
+
+	t.Run("NotServing", func(t *testing.T) {
+		td.Cmp(t, get("/livez").Code, http.StatusOK)
+		td.Cmp(t, get("/readyz").Code, http.StatusServiceUnavailable)
+	})
+
+	l.readiness.ready.Store(true)
+
+	t.Run("Ready", func(t *testing.T) {
+		td.Cmp(t, get("/readyz").Code, http.StatusOK)
+
+		w := get("/readyz?verbose")
+		td.Cmp(t, w.Code, http.StatusOK)
+		td.Cmp(t, w.Body.Bytes(), td.Smuggle(json.RawMessage(nil), td.JSON(`{
+			"status": "ok",
+			"ready": true,
+			"checks": [
+				{"name": "postgres", "status": "ok", "latency": $1},
+				{"name": "redis", "status": "ok", "latency": $1}
+			]
+		}`, td.NotEmpty())))
+	})
+
+	t.Run("DependencyFailed", func(t *testing.T) {
+		dbErr = errors.New("connection refused")
+
+		td.Cmp(t, get("/readyz").Code, http.StatusServiceUnavailable)
+
+		dbErr = nil
+
+		w := get("/readyz?verbose=1")
+		td.Cmp(t, w.Code, http.StatusOK)
+		td.Cmp(t, w.Body.Bytes(), td.Smuggle(json.RawMessage(nil), td.SuperJSONOf(`{
+			"checks": [
+				{"name": "postgres", "status": "ok", "latency": $1, "lastError": "connection refused", "lastErrorAt": $1},
+				{"name": "redis", "status": "ok", "latency": $1}
+			]
+		}`, td.NotEmpty())))
+	})
+
+	t.Run("InvalidRoute", func(t *testing.T) {
+		_, err := New(":0", WithHealthCheck(HealthCheckReadinessRoute("readyz")))
+		td.CmpContains(t, err, "invalid healt-check route")
+	})
+}
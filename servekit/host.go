@@ -0,0 +1,121 @@
+package servekit
+
+import (
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// hostRouter dispatches requests to the routers of the virtual hosts by the Host
+// header, and the requests of unknown hosts to the fallback router.
+type hostRouter struct {
+	fallback    http.Handler
+	middlewares []Middleware
+
+	mu      sync.RWMutex
+	routers map[string]chi.Router
+}
+
+func newHostRouter(fallback http.Handler) *hostRouter {
+	return &hostRouter{
+		fallback:    fallback,
+		middlewares: make([]Middleware, 0),
+		routers:     make(map[string]chi.Router),
+	}
+}
+
+func (h *hostRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if router := h.match(r.Host); router != nil {
+		router.ServeHTTP(w, r)
+		return
+	}
+
+	h.fallback.ServeHTTP(w, r)
+}
+
+// router returns the router of the host pattern, creating it if needed.
+func (h *hostRouter) router(pattern string) chi.Router {
+	pattern = strings.ToLower(pattern)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	router, ok := h.routers[pattern]
+	if !ok {
+		router = chi.NewRouter()
+		router.Use(h.middlewares...)
+		h.routers[pattern] = router
+	}
+
+	return router
+}
+
+// match returns the router of the host. The exact host pattern takes precedence
+// over the wildcard one, and the longest wildcard pattern takes precedence over
+// shorter ones. Returns nil if there is no router for the host.
+func (h *hostRouter) match(host string) chi.Router {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.routers) == 0 {
+		return nil
+	}
+
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+
+	host = strings.ToLower(host)
+
+	if router, ok := h.routers[host]; ok {
+		return router
+	}
+
+	var (
+		matched chi.Router
+		longest int
+	)
+
+	for pattern, router := range h.routers {
+		suffix, ok := strings.CutPrefix(pattern, "*")
+		if ok && strings.HasSuffix(host, suffix) && len(suffix) > longest {
+			matched, longest = router, len(suffix)
+		}
+	}
+
+	return matched
+}
+
+// hosts returns the host patterns sorted alphabetically along with their routers.
+func (h *hostRouter) hosts() ([]string, map[string]chi.Router) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	hosts := make([]string, 0, len(h.routers))
+	routers := make(map[string]chi.Router, len(h.routers))
+
+	for pattern, router := range h.routers {
+		hosts = append(hosts, pattern)
+		routers[pattern] = router
+	}
+
+	sort.Strings(hosts)
+
+	return hosts, routers
+}
+
+// MountHost mounts the handler on the route like Mount does, but only for the requests
+// to the virtual host matching the host pattern by the Host header. The pattern is either
+// an exact host name, e.g. 'api.example.com', or a wildcard, e.g. '*.example.com'.
+//
+// Requests to each of the registered virtual hosts are served only by the routes mounted
+// for it, while requests to other hosts are served by the routes mounted by Mount and the
+// builtin endpoints. Middlewares set by WithGlobalMiddlewares are applied to each virtual host.
+// Note that routes of the virtual hosts are not described by OpenAPI.
+func (l *ListenerHTTP) MountHost(host, route string, handler http.Handler, middlewares ...Middleware) {
+	mount(l.hosts.router(host), route, handler, middlewares...)
+}
@@ -0,0 +1,66 @@
+package servekit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestListenerHTTP_MountHost(t *testing.T) {
+	global := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Global", "applied")
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	l, err := New(":0", WithGlobalMiddlewares(global), WithHealthCheck())
+	td.Require(t).CmpNoError(err)
+
+	l.Mount("/", versionHandler("default"))
+	l.MountHost("api.example.com", "/", versionHandler("api"))
+	l.MountHost("*.example.com", "/", versionHandler("wildcard"))
+	l.MountHost("*.eu.example.com", "/", versionHandler("eu"))
+
+	type tcase struct {
+		host       string
+		target     string
+		wantStatus int
+		wantBody   string
+	}
+
+	tests := map[string]tcase{
+		"Default":          {host: "example.org", target: "/", wantStatus: http.StatusOK, wantBody: "default"},
+		"Builtin":          {host: "10.0.0.1:8080", target: "/livez", wantStatus: http.StatusOK},
+		"Exact":            {host: "API.example.com:443", target: "/", wantStatus: http.StatusOK, wantBody: "api"},
+		"Wildcard":         {host: "www.example.com", target: "/", wantStatus: http.StatusOK, wantBody: "wildcard"},
+		"LongestWildcard":  {host: "shop.eu.example.com", target: "/", wantStatus: http.StatusOK, wantBody: "eu"},
+		"HostHidesBuiltin": {host: "api.example.com", target: "/livez", wantStatus: http.StatusOK, wantBody: "api"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			r.Host = tc.host
+
+			w := httptest.NewRecorder()
+			l.server.Handler.ServeHTTP(w, r)
+
+			td.Cmp(t, w.Code, tc.wantStatus)
+			td.Cmp(t, w.Header().Get("X-Global"), "applied")
+
+			if tc.wantBody != "" {
+				td.Cmp(t, w.Body.String(), tc.wantBody)
+			}
+		})
+	}
+
+	routes, err := l.Routes()
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, routes, td.SuperBagOf(
+		td.Struct(Route{Listener: mainListenerName, Host: "*.eu.example.com", Pattern: "/*"}, nil),
+		td.Struct(Route{Listener: mainListenerName, Host: "api.example.com", Pattern: "/*"}, nil),
+	))
+}
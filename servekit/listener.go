@@ -2,11 +2,15 @@ package servekit
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
-	"net/http/pprof"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/VictoriaMetrics/metrics"
@@ -15,6 +19,8 @@ import (
 	"github.com/heartwilltell/bones/servekit/respond"
 	"github.com/heartwilltell/hc"
 	"github.com/heartwilltell/log"
+	"github.com/quic-go/quic-go/http3"
+	"go.uber.org/multierr"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -33,11 +39,30 @@ const (
 
 	// shutdownTimeout represents server default shutdown timeout.
 	shutdownTimeout = 5 * time.Second
+
+	// healthCheckTimeout represents default timeout of readiness dependencies checks.
+	healthCheckTimeout = 5 * time.Second
+
+	// tlsReloadInterval represents default interval of TLS certificate files polling.
+	tlsReloadInterval = 30 * time.Second
+
+	// upgradeTimeout represents default time given to the new process to start serving.
+	upgradeTimeout = 30 * time.Second
 )
 
 // Middleware represents a http.Handler middleware.
 type Middleware = func(next http.Handler) http.Handler
 
+// ShutdownHook represents a function which is called during the listener shutdown
+// after HTTP traffic is drained, e.g. to close database pools or flush error reports.
+type ShutdownHook func(ctx context.Context) error
+
+// CloserHook returns the ShutdownHook which closes the given io.Closer,
+// e.g. CloserHook(pgConn).
+func CloserHook(c io.Closer) ShutdownHook {
+	return func(context.Context) error { return c.Close() }
+}
+
 // ListenerHTTP all app logic in form of http.Handler interfaces
 // along with routing logic and HTTP transport logic.
 type ListenerHTTP struct {
@@ -45,19 +70,76 @@ type ListenerHTTP struct {
 	logger log.Logger
 	router chi.Router
 	server *http.Server
+	socket socketConfig
+
+	// hosts holds the routers of virtual hosts mounted by MountHost.
+	hosts *hostRouter
+
+	// streams tracks the long-lived streams to close them on shutdown.
+	streams *streams
+
+	// shutdown holds the graceful shutdown configuration.
+	shutdown ShutdownConfig
+
+	// readiness holds the readiness gate and named dependencies.
+	readiness *readiness
+
+	// admin represents the internal server which serves builtin
+	// endpoints when the separate admin listener is enabled.
+	admin *http.Server
+
+	// adminRouter represents the router of the admin listener.
+	adminRouter chi.Router
+
+	// h3 represents the HTTP/3 server when HTTP/3 is enabled.
+	h3 *http3.Server
+
+	// http3 holds HTTP/3 configuration.
+	http3 HTTP3Config
+
+	// stats and adminStats collect server-level metrics of the listeners.
+	stats      *serverStats
+	adminStats *serverStats
+
+	// tlsReload holds TLS certificate reload configuration.
+	tlsReload TLSReloadConfig
+
+	// upgrade holds the zero-downtime binary upgrade configuration.
+	upgrade UpgradeConfig
+
+	// openAPI holds the OpenAPI document configuration.
+	openAPI OpenAPIEndpointConfig
+
+	// addr and adminAddr hold the addresses of the bound listeners.
+	addrMu    sync.RWMutex
+	addr      net.Addr
+	adminAddr net.Addr
+
+	// bound is closed when the listeners are bound.
+	bound     chan struct{}
+	boundOnce sync.Once
+
+	// run serves the listener started by Start.
+	run *backgroundComponent
 }
 
 // New return a new instance of ListenerHTTP struct.
 func New(addr string, options ...Option[*config]) (*ListenerHTTP, error) {
 	router := chi.NewRouter()
+	hosts := newHostRouter(router)
+	streams := newStreams()
 
 	s := ListenerHTTP{
-		logger: log.NewNopLog(),
-		health: hc.NewNopChecker(),
-		router: router,
+		logger:    log.NewNopLog(),
+		health:    hc.NewNopChecker(),
+		readiness: &readiness{timeout: healthCheckTimeout},
+		router:    router,
+		hosts:     hosts,
+		streams:   streams,
+		bound:     make(chan struct{}),
 		server: &http.Server{
 			Addr:              addr,
-			Handler:           router,
+			Handler:           streams.handler(hosts),
 			ReadTimeout:       readTimeout,
 			ReadHeaderTimeout: readHeaderTimeout,
 			WriteTimeout:      writeTimeout,
@@ -65,6 +147,9 @@ func New(addr string, options ...Option[*config]) (*ListenerHTTP, error) {
 		},
 	}
 
+	s.stats = newServerStats(mainListenerName)
+	s.stats.instrument(s.server)
+
 	// To not keep a lot of unnecessary stuff on the ListenerHTTP struct instance,
 	// all the options will be applied to config struct, which will be used
 	// as the source of truth to apply the final configuration to the ListenerHTTP.
@@ -75,67 +160,340 @@ func New(addr string, options ...Option[*config]) (*ListenerHTTP, error) {
 	return &s, nil
 }
 
+// Addr returns the network address the listener is bound to.
+// Returns nil if the listener is not serving yet.
+//
+// Useful to read back the actual address when listener
+// is bound to an ephemeral port, e.g. ":0".
+func (l *ListenerHTTP) Addr() net.Addr {
+	l.addrMu.RLock()
+	defer l.addrMu.RUnlock()
+
+	return l.addr
+}
+
+// AdminAddr returns the network address the admin listener is bound to.
+// Returns nil if the admin listener is not enabled or not serving yet.
+func (l *ListenerHTTP) AdminAddr() net.Addr {
+	l.addrMu.RLock()
+	defer l.addrMu.RUnlock()
+
+	return l.adminAddr
+}
+
 func (l *ListenerHTTP) Mount(route string, handler http.Handler, middlewares ...Middleware) {
-	l.router.Route(route, func(r chi.Router) {
+	mount(l.router, route, handler, middlewares...)
+}
+
+// mount mounts the handler on the route of the router with the given middlewares.
+func mount(router chi.Router, route string, handler http.Handler, middlewares ...Middleware) {
+	router.Route(route, func(r chi.Router) {
 		r.Use(middlewares...)
 		r.Mount("/", handler)
 	})
 }
 
 // Serve listen to incoming connections and serves each request.
+//
+// Serve blocks until the given ctx is canceled and the listener is shut down.
+// The returned error wraps one of ErrBindFailed, ErrServeFailed, ErrShutdownTimeout
+// or ErrShutdownFailed, which allows the caller to decide how to exit.
 func (l *ListenerHTTP) Serve(ctx context.Context) error {
-	if l.server.Addr == "" {
-		return fmt.Errorf("invalid listener address: %s", l.server.Addr)
+	ln, err := l.listen()
+	if err != nil {
+		return l.abort(err)
 	}
 
-	g, serveCtx := errgroup.WithContext(ctx)
+	return l.serve(ctx, ln, l.server.Serve)
+}
 
-	// handle shutdown signal in the background
-	g.Go(func() error { return l.handleShutdown(serveCtx) })
+// loadKeyPair loads the TLS certificate and private key files to the server TLS config,
+// unless the config already has the certificate and the files are not given.
+// The keypair is loaded before the listener is bound, so the invalid keypair does not bind it.
+func (l *ListenerHTTP) loadKeyPair(cert, key string) error {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if l.server.TLSConfig != nil {
+		tlsConfig = l.server.TLSConfig.Clone()
+	}
 
-	g.Go(func() error {
-		l.logger.Info("ListenerHTTP started to listen on: %s", l.server.Addr)
+	hasCert := len(tlsConfig.Certificates) > 0 || tlsConfig.GetCertificate != nil || tlsConfig.GetConfigForClient != nil
+	if hasCert && cert == "" && key == "" {
+		return nil
+	}
+
+	keyPair, err := tls.LoadX509KeyPair(cert, key)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrServeFailed, err)
+	}
+
+	tlsConfig.Certificates = []tls.Certificate{keyPair}
+	l.server.TLSConfig = tlsConfig
+
+	return nil
+}
+
+// ServeTLS listen to incoming TLS connections and serves each request.
+// Takes cert and key - paths to the TLS certificate and private key files.
+//
+// If the TLS reload is enabled by WithTLSReload option, the certificate and key
+// files are watched and the keypair is swapped without the listener restart.
+//
+// If the HTTP/3 is enabled by WithHTTP3 option, the HTTP/3 (QUIC) listener serves
+// the same handler alongside, and the HTTP/3 support is announced by Alt-Svc header.
+//
+// The returned error behaves the same way as the error returned by Serve.
+func (l *ListenerHTTP) ServeTLS(ctx context.Context, cert, key string) error {
+	background := make([]func(ctx context.Context) error, 0, 2)
+
+	if l.tlsReload.enable {
+		reloader, err := newCertReloader(cert, key, l.logger)
+		if err != nil {
+			return l.abort(fmt.Errorf("%w: %w", ErrServeFailed, err))
+		}
+
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if l.server.TLSConfig != nil {
+			tlsConfig = l.server.TLSConfig.Clone()
+		}
+
+		tlsConfig.GetCertificate = reloader.GetCertificate
+		l.server.TLSConfig = tlsConfig
 
-		if err := l.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			return fmt.Errorf("listener failed: %w", err)
+		background = append(background, func(ctx context.Context) error {
+			return reloader.watch(ctx, l.tlsReload.interval)
+		})
+	} else if err := l.loadKeyPair(cert, key); err != nil {
+		return l.abort(err)
+	}
+
+	// The certificate is served by the tls.Config.
+	cert, key = "", ""
+
+	ln, err := l.listen()
+	if err != nil {
+		return l.abort(err)
+	}
+
+	if l.http3.enable {
+		h3, serveHTTP3, err := l.listenHTTP3(cert, key)
+		if err != nil {
+			_ = ln.Close()
+			return l.abort(err)
 		}
 
+		l.h3 = h3
+		l.server.Handler = altSvcHandler(h3, l.server.Handler)
+
+		background = append(background, serveHTTP3)
+	}
+
+	return l.serve(ctx, ln, func(ln net.Listener) error { return l.server.ServeTLS(ln, cert, key) }, background...)
+}
+
+// ServeListener serves each request accepted by the given pre-bound ln listener.
+// The listener will be closed when the ctx is canceled and server is shut down.
+//
+// The returned error behaves the same way as the error returned by Serve.
+func (l *ListenerHTTP) ServeListener(ctx context.Context, ln net.Listener) error {
+	if ln == nil {
+		return l.abort(fmt.Errorf("%w: listener is nil", ErrBindFailed))
+	}
+
+	return l.serve(ctx, ln, l.server.Serve)
+}
+
+// Start binds the listener and serves requests in the background until Stop is called,
+// which makes the listener the BackgroundComponent managed by Run. Start returns
+// as soon as the listeners are bound. Use Background to run ServeTLS by Run.
+func (l *ListenerHTTP) Start(ctx context.Context) error {
+	ln, err := l.listen()
+	if err != nil {
+		return l.abort(err)
+	}
+
+	l.run = &backgroundComponent{fn: func(ctx context.Context) error { return l.serve(ctx, ln, l.server.Serve) }}
+	_ = l.run.Start(ctx)
+
+	select {
+	case <-l.bound:
 		return nil
-	})
 
-	if err := g.Wait(); err != nil {
-		l.logger.Error("Force exit! %s", err.Error())
-		panic(err)
+	case err := <-l.run.Wait():
+		return err
 	}
+}
 
-	l.logger.Info("Bye!")
+// Stop shuts down the listener started by Start and waits until it is shut down or the ctx is done.
+// The returned error behaves the same way as the error returned by Serve.
+func (l *ListenerHTTP) Stop(ctx context.Context) error {
+	if l.run == nil {
+		return nil
+	}
 
-	return nil
+	return l.run.Stop(ctx)
 }
 
-func (l *ListenerHTTP) ServeTLS(ctx context.Context, cert, key string) error {
-	if l.server.Addr == "" {
-		return fmt.Errorf("invalid listener address: %s", l.server.Addr)
+// Wait returns the channel which receives the error, or nil,
+// when the listener started by Start stops serving before Stop is called,
+// e.g. when it fails or hands off the listeners on upgrade.
+func (l *ListenerHTTP) Wait() <-chan error {
+	if l.run == nil {
+		return nil
+	}
+
+	return l.run.Wait()
+}
+
+// listen binds the listener to the configured network address.
+// If socket activation is enabled, the socket passed by systemd is used instead.
+// The listener handed off by the parent process during the upgrade takes precedence.
+func (l *ListenerHTTP) listen() (net.Listener, error) {
+	if ln, err := inheritedListener(mainListenerName); err != nil || ln != nil {
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrBindFailed, err)
+		}
+
+		return ln, nil
+	}
+
+	if !l.socket.activation {
+		return l.listenAddr(l.server.Addr)
+	}
+
+	ln, err := listenActivated(l.socket.activationName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBindFailed, err)
 	}
 
-	g, sctx := errgroup.WithContext(ctx)
+	return ln, nil
+}
+
+// listenAddr binds the listener to the given network address.
+// The address with 'unix:' prefix binds the listener to the unix domain socket.
+func (l *ListenerHTTP) listenAddr(addr string) (net.Listener, error) {
+	var (
+		ln  net.Listener
+		err error
+	)
+
+	switch {
+	case strings.HasPrefix(addr, unixAddrPrefix):
+		ln, err = listenUnix(strings.TrimPrefix(addr, unixAddrPrefix), l.socket.unixMode)
+
+	case addr == "":
+		return nil, fmt.Errorf("%w: invalid listener address: %s", ErrBindFailed, addr)
+
+	default:
+		ln, err = net.Listen("tcp", addr)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBindFailed, err)
+	}
+
+	return ln, nil
+}
+
+// serve runs the given serveFn on the bound ln listener, and handles
+// the shutdown of the listener when ctx is canceled. The given background
+// tasks run alongside the listener until it is shut down.
+//
+// If the admin listener is enabled, it is bound and served
+// alongside the main listener and shut down together with it.
+func (l *ListenerHTTP) serve(ctx context.Context, ln net.Listener, serveFn func(ln net.Listener) error, background ...func(ctx context.Context) error) error {
+	var adminLn net.Listener
+
+	if l.admin != nil {
+		var err error
+
+		if adminLn, err = inheritedListener(adminListenerName); err != nil {
+			err = fmt.Errorf("%w: %w", ErrBindFailed, err)
+		} else if adminLn == nil {
+			adminLn, err = l.listenAddr(l.admin.Addr)
+		}
+
+		if err != nil {
+			_ = ln.Close()
+			return l.abort(err)
+		}
+	}
+
+	l.addrMu.Lock()
+	l.addr = ln.Addr()
+
+	if adminLn != nil {
+		l.adminAddr = adminLn.Addr()
+	}
+
+	l.addrMu.Unlock()
+
+	l.boundOnce.Do(func() { close(l.bound) })
+
+	// Open the readiness gate since listeners are bound.
+	l.readiness.ready.Store(true)
+
+	// Tell the parent process the listeners are taken over, if any.
+	if err := notifyUpgradeReady(); err != nil {
+		l.logger.Error("ListenerHTTP failed to notify the parent process: %s", err.Error())
+	}
+
+	// The upgrade stops the listener when the new process is ready.
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	if l.upgrade.enable {
+		mainHandoff := newHandoffListener(ln)
+		ln = mainHandoff
+
+		listeners := []namedListener{{name: mainListenerName, ln: mainHandoff, stats: l.stats}}
+
+		if adminLn != nil {
+			adminHandoff := newHandoffListener(adminLn)
+			adminLn = adminHandoff
+
+			listeners = append(listeners, namedListener{name: adminListenerName, ln: adminHandoff, stats: l.adminStats})
+		}
+
+		background = append(background, func(ctx context.Context) error {
+			return l.watchUpgrade(ctx, stop, listeners)
+		})
+	}
+
+	g, serveCtx := errgroup.WithContext(ctx)
 
 	// handle shutdown signal in the background
-	g.Go(func() error { return l.handleShutdown(sctx) })
+	g.Go(func() error { return l.handleShutdown(serveCtx) })
+
+	for _, task := range background {
+		task := task
+		g.Go(func() error { return task(serveCtx) })
+	}
+
+	if adminLn != nil {
+		g.Go(func() error {
+			l.logger.Info("ListenerHTTP admin started to listen on: %s", adminLn.Addr().String())
+
+			if err := l.admin.Serve(adminLn); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("%w: admin: %w", ErrServeFailed, err)
+			}
+
+			return nil
+		})
+	}
 
 	g.Go(func() error {
-		l.logger.Info("ListenerHTTP started to listen on: %s", l.server.Addr)
+		l.logger.Info("ListenerHTTP started to listen on: %s", ln.Addr().String())
 
-		if err := l.server.ListenAndServeTLS(cert, key); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			return fmt.Errorf("listener failed: %w", err)
+		if err := serveFn(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("%w: %w", ErrServeFailed, err)
 		}
 
 		return nil
 	})
 
 	if err := g.Wait(); err != nil {
-		l.logger.Error("Force exit! %s", err.Error())
-		panic(err)
+		l.logger.Error("ListenerHTTP stopped with error: %s", err.Error())
+		return err
 	}
 
 	l.logger.Info("Bye!")
@@ -162,20 +520,103 @@ func (l *ListenerHTTP) healthCheck(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleShutdown blocks until select statement receives a signal from
-// ctx.Done, after that new context.WithTimeout will be created and passed to
-// http.Server Shutdown method.
+// ctx.Done, after that the readiness gate is closed and the listener waits
+// for the drain period, so load balancers stop routing the traffic to it.
+// Then new context.WithTimeout will be created and passed to http.Server
+// Shutdown method. The admin listener is shut down after the main one,
+// so the builtin endpoints stay available while the main listener drains.
+// When HTTP traffic is drained, the shutdown hooks are executed in order.
 //
-// If Shutdown method returns non nil error, program will panic immediately.
+// If Shutdown method returns non nil error, the error wrapped
+// with ErrShutdownTimeout or ErrShutdownFailed will be returned.
+// Errors of both listeners and shutdown hooks are aggregated.
 func (l *ListenerHTTP) handleShutdown(ctx context.Context) error {
 	<-ctx.Done()
 
+	// Close the readiness gate to stop receiving the traffic.
+	l.readiness.ready.Store(false)
+
+	if l.shutdown.drain > 0 {
+		l.logger.Info("Draining the listener for %s before shutdown", l.shutdown.drain.String())
+		time.Sleep(l.shutdown.drain)
+	}
+
 	l.logger.Info("Shutting down the listener!")
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	var shutdownErr error
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), l.shutdown.timeout)
 	defer cancel()
 
-	if err := l.server.Shutdown(shutdownCtx); err != nil {
-		return fmt.Errorf("failed to shutdown the listener gracefully: %w", err)
+	start := time.Now()
+
+	// Signal the long-lived streams to close, since
+	// the server does not wait for hijacked connections.
+	l.streams.close()
+
+	if err := shutdown(shutdownCtx, l.server); err != nil {
+		multierr.AppendInto(&shutdownErr, err)
+	}
+
+	if err := l.streams.wait(shutdownCtx); err != nil {
+		multierr.AppendInto(&shutdownErr, fmt.Errorf("%w: streams: %w", ErrShutdownTimeout, err))
+	}
+
+	l.stats.observeShutdown(start)
+
+	if l.h3 != nil {
+		if err := l.h3.Close(); err != nil {
+			multierr.AppendInto(&shutdownErr, fmt.Errorf("%w: http3: %w", ErrShutdownFailed, err))
+		}
+	}
+
+	if l.admin != nil {
+		if err := shutdown(shutdownCtx, l.admin); err != nil {
+			multierr.AppendInto(&shutdownErr, fmt.Errorf("admin: %w", err))
+		}
+
+		l.adminStats.observeShutdown(start)
+	}
+
+	// Hooks are executed even if the listener has not been drained in time.
+	multierr.AppendInto(&shutdownErr, l.runShutdownHooks())
+
+	return shutdownErr
+}
+
+// abort executes the shutdown hooks when the listener fails before it starts serving,
+// e.g. to bind, since the resources they release are acquired before the listener is served.
+// Returns the given err along with the errors of the hooks.
+func (l *ListenerHTTP) abort(err error) error {
+	multierr.AppendInto(&err, l.runShutdownHooks())
+	return err
+}
+
+// runShutdownHooks executes the shutdown hooks in order with their own timeout,
+// and aggregates their errors.
+func (l *ListenerHTTP) runShutdownHooks() error {
+	ctx, cancel := context.WithTimeout(context.Background(), l.shutdown.timeout)
+	defer cancel()
+
+	var hooksErr error
+
+	for i, hook := range l.shutdown.hooks {
+		if err := hook(ctx); err != nil {
+			multierr.AppendInto(&hooksErr, fmt.Errorf("%w: shutdown hook #%d: %w", ErrShutdownFailed, i, err))
+		}
+	}
+
+	return hooksErr
+}
+
+// shutdown gracefully shuts down the server and classifies the error.
+func shutdown(ctx context.Context, server *http.Server) error {
+	if err := server.Shutdown(ctx); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("%w: %w", ErrShutdownTimeout, err)
+		}
+
+		return fmt.Errorf("%w: %w", ErrShutdownFailed, err)
 	}
 
 	return nil
@@ -193,12 +634,38 @@ func (l *ListenerHTTP) configure(options ...Option[*config]) error {
 
 		globalMiddlewares: make([]Middleware, 0),
 
+		shutdown: ShutdownConfig{
+			timeout: shutdownTimeout,
+			drain:   0,
+			hooks:   make([]ShutdownHook, 0),
+		},
+
+		tlsReload: TLSReloadConfig{
+			enable:   false,
+			interval: tlsReloadInterval,
+		},
+
+		upgrade: UpgradeConfig{
+			enable:  false,
+			args:    make([]string, 0),
+			timeout: upgradeTimeout,
+		},
+
+		mutualTLS: MutualTLSConfig{
+			enable:     false,
+			clientAuth: tls.RequireAndVerifyClientCert,
+		},
+
 		health: HealthEndpointConfig{
 			enable:                    false,
 			accessLogsEnabled:         false,
 			metricsForEndpointEnabled: false,
 			route:                     "/health",
+			livenessRoute:             "/livez",
+			readinessRoute:            "/readyz",
 			healthChecker:             hc.NewNopChecker(),
+			dependencies:              make([]*dependency, 0),
+			timeout:                   healthCheckTimeout,
 		},
 
 		metrics: MetricsEndpointConfig{
@@ -211,7 +678,25 @@ func (l *ListenerHTTP) configure(options ...Option[*config]) error {
 		profiler: ProfilerEndpointConfig{
 			enable:            false,
 			accessLogsEnabled: false,
-			route:             "/profiler",
+			route:             "/debug/pprof",
+			guards:            make([]Middleware, 0),
+			blockRate:         -1,
+			mutexFraction:     -1,
+		},
+
+		routes: RoutesEndpointConfig{
+			enable:            false,
+			accessLogsEnabled: false,
+			route:             "/debug/routes",
+			guards:            make([]Middleware, 0),
+		},
+
+		openAPI: OpenAPIEndpointConfig{
+			enable:            false,
+			accessLogsEnabled: false,
+			route:             "/openapi.json",
+			title:             "API",
+			version:           "0.0.0",
 		},
 	}
 
@@ -223,8 +708,66 @@ func (l *ListenerHTTP) configure(options ...Option[*config]) error {
 	// Apply logger settings.
 	l.logger = cfg.logger
 
+	// Apply shutdown settings.
+	if cfg.shutdown.timeout <= 0 {
+		return fmt.Errorf("invalid shutdown timeout: %s (should be positive)", cfg.shutdown.timeout)
+	}
+
+	if cfg.shutdown.drain < 0 {
+		return fmt.Errorf("invalid shutdown drain period: %s (should not be negative)", cfg.shutdown.drain)
+	}
+
+	l.shutdown = cfg.shutdown
+
+	// Apply HTTP/3 settings.
+	l.http3 = cfg.http3
+
+	// Apply socket settings.
+	l.socket = cfg.socket
+
+	// Apply TLS reload settings.
+	if cfg.tlsReload.enable && cfg.tlsReload.interval <= 0 {
+		return fmt.Errorf("invalid TLS reload interval: %s (should be positive)", cfg.tlsReload.interval)
+	}
+
+	l.tlsReload = cfg.tlsReload
+
+	// Apply upgrade settings.
+	if cfg.upgrade.enable {
+		if !upgradeSupported {
+			return errors.New("invalid upgrade: not supported on this platform")
+		}
+
+		if cfg.http3.enable {
+			return errors.New("invalid upgrade: not compatible with HTTP/3")
+		}
+
+		if cfg.upgrade.timeout <= 0 {
+			return fmt.Errorf("invalid upgrade timeout: %s (should be positive)", cfg.upgrade.timeout)
+		}
+	}
+
+	l.upgrade = cfg.upgrade
+
+	// Apply mutual TLS settings.
+	if cfg.mutualTLS.enable {
+		clientCAs, err := loadCertPool(cfg.mutualTLS.caFile)
+		if err != nil {
+			return fmt.Errorf("invalid mutual TLS CA bundle: %w", err)
+		}
+
+		l.server.TLSConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			ClientCAs:  clientCAs,
+			ClientAuth: cfg.mutualTLS.clientAuth,
+		}
+	}
+
 	// Apply health checker settings.
 	l.health = cfg.health.healthChecker
+	l.readiness.dependencies = cfg.health.dependencies
+	l.readiness.timeout = cfg.health.timeout
+	l.readiness.verbose = cfg.health.verbose
 
 	// Apply server timeouts.
 	l.server.ReadTimeout = cfg.readTimeout
@@ -234,17 +777,46 @@ func (l *ListenerHTTP) configure(options ...Option[*config]) error {
 
 	// Apply router-wide middleware.
 	l.router.Use(cfg.globalMiddlewares...)
+	l.hosts.middlewares = cfg.globalMiddlewares
+
+	// Builtin endpoints are served by the main router, unless the admin
+	// listener is enabled. In that case they are served by the separate
+	// admin router, to which router-wide middlewares are not applied.
+	builtin := l.router
+
+	if cfg.adminAddr != "" {
+		builtin = chi.NewRouter()
+		l.adminRouter = builtin
+
+		l.admin = &http.Server{
+			Addr:              cfg.adminAddr,
+			Handler:           builtin,
+			ReadTimeout:       cfg.readTimeout,
+			ReadHeaderTimeout: cfg.readHeaderTimeout,
+			WriteTimeout:      cfg.writeTimeout,
+			IdleTimeout:       cfg.idleTimeout,
+		}
+
+		l.adminStats = newServerStats(adminListenerName)
+		l.adminStats.instrument(l.admin)
+	}
 
 	if cfg.health.enable {
-		if cfg.health.route == "" {
-			return fmt.Errorf("invalid healt-check route: %s (should not be empty)", cfg.health.route)
+		for _, route := range []string{cfg.health.route, cfg.health.livenessRoute, cfg.health.readinessRoute} {
+			if route == "" {
+				return fmt.Errorf("invalid healt-check route: %s (should not be empty)", route)
+			}
+
+			if !strings.HasPrefix(route, "/") {
+				return fmt.Errorf("invalid healt-check route: %s (route should start with '/' slash)", route)
+			}
 		}
 
-		if !strings.HasPrefix(cfg.health.route, "/") {
-			return fmt.Errorf("invalid healt-check route: %s (route should start with '/' slash)", cfg.health.route)
+		if cfg.health.timeout <= 0 {
+			return fmt.Errorf("invalid healt-check timeout: %s (should be positive)", cfg.health.timeout)
 		}
 
-		l.router.Group(func(g chi.Router) {
+		builtin.Group(func(g chi.Router) {
 			if cfg.health.accessLogsEnabled {
 				g.Use(middleware.LoggingMiddleware(l.logger))
 			}
@@ -254,6 +826,8 @@ func (l *ListenerHTTP) configure(options ...Option[*config]) error {
 			}
 
 			g.Get(cfg.health.route, l.healthCheck)
+			g.Get(cfg.health.livenessRoute, l.liveness)
+			g.Get(cfg.health.readinessRoute, l.readinessCheck)
 		})
 	}
 
@@ -266,7 +840,7 @@ func (l *ListenerHTTP) configure(options ...Option[*config]) error {
 			return fmt.Errorf("invalid metrics route: %s (route should start with '/' slash)", cfg.metrics.route)
 		}
 
-		l.router.Group(func(g chi.Router) {
+		builtin.Group(func(g chi.Router) {
 			if cfg.metrics.accessLogsEnabled {
 				g.Use(middleware.LoggingMiddleware(l.logger))
 			}
@@ -280,21 +854,78 @@ func (l *ListenerHTTP) configure(options ...Option[*config]) error {
 	}
 
 	if cfg.profiler.enable {
-		l.router.Group(func(g chi.Router) {
+		if cfg.profiler.route == "" {
+			return fmt.Errorf("invalid profiler route: %s (should not be empty)", cfg.profiler.route)
+		}
+
+		if !strings.HasPrefix(cfg.profiler.route, "/") {
+			return fmt.Errorf("invalid profiler route: %s (route should start with '/' slash)", cfg.profiler.route)
+		}
+
+		if cfg.profiler.blockRate >= 0 {
+			setBlockProfileRate(cfg.profiler.blockRate)
+		}
+
+		if cfg.profiler.mutexFraction >= 0 {
+			runtime.SetMutexProfileFraction(cfg.profiler.mutexFraction)
+		}
+
+		builtin.Group(func(g chi.Router) {
 			if cfg.profiler.accessLogsEnabled {
 				g.Use(middleware.LoggingMiddleware(l.logger))
 			}
 
-			g.Route("/debug/pprof", func(profiler chi.Router) {
-				profiler.HandleFunc("/", pprof.Index)
-				profiler.HandleFunc("/cmdline", pprof.Cmdline)
-				profiler.HandleFunc("/profile", pprof.Profile)
-				profiler.HandleFunc("/symbol", pprof.Symbol)
-				profiler.HandleFunc("/trace", pprof.Trace)
-			})
+			g.Use(cfg.profiler.guards...)
+			g.Mount(cfg.profiler.route, profilerRouter())
+		})
+	}
+
+	if cfg.routes.enable {
+		if cfg.routes.route == "" {
+			return fmt.Errorf("invalid routes route: %s (should not be empty)", cfg.routes.route)
+		}
+
+		if !strings.HasPrefix(cfg.routes.route, "/") {
+			return fmt.Errorf("invalid routes route: %s (route should start with '/' slash)", cfg.routes.route)
+		}
+
+		builtin.Group(func(g chi.Router) {
+			if cfg.routes.accessLogsEnabled {
+				g.Use(middleware.LoggingMiddleware(l.logger))
+			}
+
+			g.Use(cfg.routes.guards...)
+			g.Get(cfg.routes.route, l.serveRoutes)
+		})
+	}
+
+	// Apply OpenAPI settings.
+	l.openAPI = cfg.openAPI
+
+	if cfg.openAPI.enable {
+		if cfg.openAPI.route == "" {
+			return fmt.Errorf("invalid OpenAPI route: %s (should not be empty)", cfg.openAPI.route)
+		}
+
+		if !strings.HasPrefix(cfg.openAPI.route, "/") {
+			return fmt.Errorf("invalid OpenAPI route: %s (route should start with '/' slash)", cfg.openAPI.route)
+		}
+
+		builtin.Group(func(g chi.Router) {
+			if cfg.openAPI.accessLogsEnabled {
+				g.Use(middleware.LoggingMiddleware(l.logger))
+			}
+
+			g.Get(cfg.openAPI.route, l.serveOpenAPI)
 		})
 	}
 
+	if cfg.h2c {
+		if err := enableH2C(l.server); err != nil {
+			return fmt.Errorf("failed to enable h2c: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -315,6 +946,31 @@ type config struct {
 	// idleTimeout represents the http.Server IdleTimeout.
 	idleTimeout time.Duration
 
+	// shutdown holds the graceful shutdown configuration.
+	shutdown ShutdownConfig
+
+	// socket holds the configuration of the listening socket.
+	socket socketConfig
+
+	// tlsReload holds configuration of TLS certificate reload.
+	tlsReload TLSReloadConfig
+
+	// upgrade holds configuration of the zero-downtime binary upgrade.
+	upgrade UpgradeConfig
+
+	// mutualTLS holds configuration of mutual TLS.
+	mutualTLS MutualTLSConfig
+
+	// h2c turns on HTTP/2 over cleartext TCP.
+	h2c bool
+
+	// http3 holds HTTP/3 configuration.
+	http3 HTTP3Config
+
+	// adminAddr represents the address of the admin listener
+	// which serves builtin endpoints. Empty means disabled.
+	adminAddr string
+
 	// globalMiddlewares holds a set of router-wide middlewares
 	// which applies to each endpoint.
 	globalMiddlewares []Middleware
@@ -327,4 +983,10 @@ type config struct {
 
 	// profiler holds configuration fot profiler endpoint.
 	profiler ProfilerEndpointConfig
+
+	// routes holds configuration for routes listing endpoint.
+	routes RoutesEndpointConfig
+
+	// openAPI holds configuration for OpenAPI document endpoint.
+	openAPI OpenAPIEndpointConfig
 }
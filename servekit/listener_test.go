@@ -1,10 +1,14 @@
 package servekit
 
 import (
+	"context"
+	"errors"
+	"net"
 	"net/http"
+	"path/filepath"
 	"testing"
+	"time"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/heartwilltell/hc"
 	"github.com/heartwilltell/log"
 	"github.com/maxatome/go-testdeep/td"
@@ -14,7 +18,7 @@ func TestNew(t *testing.T) {
 	type tcase struct {
 		addr    string
 		options []Option[*config]
-		want    *ListenerHTTP
+		want    any
 		wantErr error
 	}
 
@@ -22,15 +26,19 @@ func TestNew(t *testing.T) {
 		"OK": {
 			addr:    ":8080",
 			options: nil,
-			want: &ListenerHTTP{
+			want: td.Struct(&ListenerHTTP{
 				health: hc.NewNopChecker(),
 				logger: log.NewNopLog(),
-				router: chi.NewRouter(),
-				server: &http.Server{
-					Addr:    ":8080",
-					Handler: chi.NewRouter(),
-				},
-			},
+			}, td.StructFields{
+				"router": td.NotNil(),
+				"server": td.Struct(&http.Server{
+					Addr:              ":8080",
+					ReadTimeout:       readTimeout,
+					ReadHeaderTimeout: readHeaderTimeout,
+					WriteTimeout:      writeTimeout,
+					IdleTimeout:       idleTimeout,
+				}, td.StructFields{"Handler": td.NotNil()}),
+			}),
 			wantErr: nil,
 		},
 	}
@@ -43,3 +51,307 @@ func TestNew(t *testing.T) {
 		})
 	}
 }
+
+func TestListenerHTTP_Serve(t *testing.T) {
+	t.Run("BindFailed", func(t *testing.T) {
+		busy, err := net.Listen("tcp", "127.0.0.1:0")
+		td.Require(t).CmpNoError(err)
+
+		defer busy.Close()
+
+		l, err := New(busy.Addr().String())
+		td.Require(t).CmpNoError(err)
+
+		td.Cmp(t, l.Serve(context.Background()), td.ErrorIs(ErrBindFailed))
+	})
+
+	t.Run("EmptyAddress", func(t *testing.T) {
+		l, err := New("")
+		td.Require(t).CmpNoError(err)
+
+		td.Cmp(t, l.Serve(context.Background()), td.ErrorIs(ErrBindFailed))
+	})
+
+	t.Run("ServeFailed", func(t *testing.T) {
+		free, err := net.Listen("tcp", "127.0.0.1:0")
+		td.Require(t).CmpNoError(err)
+
+		addr := free.Addr().String()
+		free.Close()
+
+		l, err := New(addr)
+		td.Require(t).CmpNoError(err)
+
+		td.Cmp(t, l.ServeTLS(context.Background(), "missing.crt", "missing.key"), td.ErrorIs(ErrServeFailed))
+		td.CmpNil(t, l.Addr())
+
+		// The listener is not bound by the invalid keypair.
+		ln, err := net.Listen("tcp", addr)
+		td.Require(t).CmpNoError(err)
+		ln.Close()
+	})
+
+	t.Run("Shutdown", func(t *testing.T) {
+		l, err := New("127.0.0.1:0")
+		td.Require(t).CmpNoError(err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		td.CmpNoError(t, l.Serve(ctx))
+	})
+
+	t.Run("SocketActivationFailed", func(t *testing.T) {
+		l, err := New("", WithSocketActivation(""))
+		td.Require(t).CmpNoError(err)
+
+		td.Cmp(t, l.Serve(context.Background()), td.ErrorIs(ErrBindFailed))
+	})
+
+	t.Run("UnixSocket", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "listener.sock")
+
+		l, err := New("unix:" + path)
+		td.Require(t).CmpNoError(err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- l.Serve(ctx) }()
+
+		td.Require(t).True(waitFor(func() bool { return l.Addr() != nil }))
+		td.Cmp(t, l.Addr().String(), path)
+
+		cancel()
+		td.CmpNoError(t, <-errCh)
+	})
+}
+
+func TestListenerHTTP_ServeListener(t *testing.T) {
+	l, err := New("")
+	td.Require(t).CmpNoError(err)
+
+	l.Mount("/", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusTeapot) }))
+
+	td.Cmp(t, l.Addr(), nil)
+	td.Cmp(t, l.ServeListener(context.Background(), nil), td.ErrorIs(ErrBindFailed))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	td.Require(t).CmpNoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- l.ServeListener(ctx, ln) }()
+
+	td.Require(t).True(waitFor(func() bool { return l.Addr() != nil }))
+	td.Cmp(t, l.Addr().String(), ln.Addr().String())
+
+	resp, err := testClient.Get("http://" + l.Addr().String() + "/") //nolint:noctx
+	td.Require(t).CmpNoError(err)
+	td.CmpNoError(t, resp.Body.Close())
+	td.Cmp(t, resp.StatusCode, http.StatusTeapot)
+	td.CmpTrue(t, l.readiness.ready.Load())
+
+	cancel()
+	td.CmpNoError(t, <-errCh)
+	td.CmpFalse(t, l.readiness.ready.Load())
+}
+
+// testClient represents HTTP client which does not keep connections alive,
+// to not hold up the listener graceful shutdown by idle connections.
+var testClient = http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+// waitFor polls the condition until it is true or one second passes.
+func waitFor(condition func() bool) bool {
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+		if condition() {
+			return true
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	return false
+}
+
+func TestListenerHTTP_AdminListener(t *testing.T) {
+	t.Run("BindFailed", func(t *testing.T) {
+		busy, err := net.Listen("tcp", "127.0.0.1:0")
+		td.Require(t).CmpNoError(err)
+
+		defer busy.Close()
+
+		l, err := New("127.0.0.1:0", WithAdminListener(busy.Addr().String()))
+		td.Require(t).CmpNoError(err)
+
+		td.Cmp(t, l.Serve(context.Background()), td.ErrorIs(ErrBindFailed))
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		l, err := New("127.0.0.1:0",
+			WithAdminListener("127.0.0.1:0"),
+			WithHealthCheck(),
+			WithMetrics(),
+		)
+		td.Require(t).CmpNoError(err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- l.Serve(ctx) }()
+
+		td.Require(t).True(waitFor(func() bool { return l.AdminAddr() != nil }))
+
+		get := func(addr net.Addr, route string) int {
+			resp, err := testClient.Get("http://" + addr.String() + route) //nolint:noctx
+			td.Require(t).CmpNoError(err)
+			td.CmpNoError(t, resp.Body.Close())
+
+			return resp.StatusCode
+		}
+
+		td.Cmp(t, get(l.Addr(), "/metrics"), http.StatusNotFound)
+		td.Cmp(t, get(l.Addr(), "/health"), http.StatusNotFound)
+		td.Cmp(t, get(l.AdminAddr(), "/metrics"), http.StatusOK)
+		td.Cmp(t, get(l.AdminAddr(), "/health"), http.StatusOK)
+
+		cancel()
+		td.CmpNoError(t, <-errCh)
+	})
+}
+
+func TestListenerHTTP_Shutdown(t *testing.T) {
+	t.Run("Hooks", func(t *testing.T) {
+		var calls []string
+
+		errHook := errors.New("hook failed")
+
+		l, err := New("127.0.0.1:0",
+			WithOnShutdown(
+				func(context.Context) error { calls = append(calls, "first"); return nil },
+				func(context.Context) error { calls = append(calls, "second"); return errHook },
+			),
+			WithOnShutdown(CloserHook(closerFunc(func() error { calls = append(calls, "closer"); return nil }))),
+		)
+		td.Require(t).CmpNoError(err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err = l.Serve(ctx)
+		td.Cmp(t, err, td.ErrorIs(ErrShutdownFailed))
+		td.Cmp(t, err, td.ErrorIs(errHook))
+		td.Cmp(t, calls, []string{"first", "second", "closer"})
+	})
+
+	t.Run("BindFailed", func(t *testing.T) {
+		busy, err := net.Listen("tcp", "127.0.0.1:0")
+		td.Require(t).CmpNoError(err)
+
+		defer busy.Close()
+
+		errHook := errors.New("hook failed")
+
+		options := map[string][]ListenerOption{
+			"Main":  nil,
+			"Admin": {WithAdminListener(busy.Addr().String())},
+		}
+
+		for name, opts := range options {
+			t.Run(name, func(t *testing.T) {
+				addr := "127.0.0.1:0"
+				if opts == nil {
+					addr = busy.Addr().String()
+				}
+
+				hookCalled := false
+
+				l, err := New(addr, append(opts, WithOnShutdown(func(context.Context) error {
+					hookCalled = true
+					return errHook
+				}))...)
+				td.Require(t).CmpNoError(err)
+
+				// The hooks release the resources even if the listener has never served.
+				err = l.Serve(context.Background())
+				td.Cmp(t, err, td.ErrorIs(ErrBindFailed))
+				td.Cmp(t, err, td.ErrorIs(errHook))
+				td.CmpTrue(t, hookCalled)
+			})
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		hookCalled := false
+
+		l, err := New("127.0.0.1:0",
+			WithShutdownTimeout(50*time.Millisecond),
+			WithOnShutdown(func(context.Context) error { hookCalled = true; return nil }),
+		)
+		td.Require(t).CmpNoError(err)
+
+		started, release := make(chan struct{}), make(chan struct{})
+		defer close(release)
+
+		l.Mount("/", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			close(started)
+			<-release
+		}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- l.Serve(ctx) }()
+
+		td.Require(t).True(waitFor(func() bool { return l.Addr() != nil }))
+
+		go func() { _, _ = testClient.Get("http://" + l.Addr().String()) }() //nolint:bodyclose,noctx
+
+		<-started
+		cancel()
+
+		td.Cmp(t, <-errCh, td.ErrorIs(ErrShutdownTimeout))
+		td.CmpTrue(t, hookCalled)
+	})
+
+	t.Run("Drain", func(t *testing.T) {
+		l, err := New("127.0.0.1:0", WithShutdownDrain(200*time.Millisecond), WithHealthCheck())
+		td.Require(t).CmpNoError(err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- l.Serve(ctx) }()
+
+		td.Require(t).True(waitFor(func() bool { return l.Addr() != nil }))
+
+		cancel()
+
+		// Still serving during the drain period, but not ready.
+		td.CmpTrue(t, waitFor(func() bool { return !l.readiness.ready.Load() }))
+
+		resp, err := testClient.Get("http://" + l.Addr().String() + "/readyz") //nolint:noctx
+		td.Require(t).CmpNoError(err)
+		td.CmpNoError(t, resp.Body.Close())
+		td.Cmp(t, resp.StatusCode, http.StatusServiceUnavailable)
+
+		td.CmpNoError(t, <-errCh)
+	})
+
+	t.Run("InvalidConfig", func(t *testing.T) {
+		_, err := New(":0", WithShutdownTimeout(0))
+		td.CmpContains(t, err, "invalid shutdown timeout")
+
+		_, err = New(":0", WithShutdownDrain(-time.Second))
+		td.CmpContains(t, err, "invalid shutdown drain period")
+	})
+}
+
+// closerFunc represents an adapter to use ordinary function as io.Closer.
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
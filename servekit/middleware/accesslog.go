@@ -0,0 +1,365 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/heartwilltell/bones/ctxkit"
+	"github.com/heartwilltell/bones/errkit"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogFormat represents the format of the access log line.
+type LogFormat uint8
+
+const (
+	// LogFormatJSON represents the access log line encoded as JSON object.
+	LogFormatJSON LogFormat = iota
+
+	// LogFormatLogfmt represents the access log line encoded as logfmt key=value pairs.
+	LogFormatLogfmt
+)
+
+// AccessLogField represents the field of the access log line.
+type AccessLogField string
+
+const (
+	// FieldTime represents the time the request has been received, in RFC 3339 format.
+	FieldTime AccessLogField = "time"
+
+	// FieldMethod represents the method of the request.
+	FieldMethod AccessLogField = "method"
+
+	// FieldRoute represents the chi route pattern which matched the request, e.g. "/users/{id}".
+	FieldRoute AccessLogField = "route"
+
+	// FieldPath represents the URL path of the request.
+	FieldPath AccessLogField = "path"
+
+	// FieldStatus represents the status of the response.
+	FieldStatus AccessLogField = "status"
+
+	// FieldBytes represents the number of bytes of the response body.
+	FieldBytes AccessLogField = "bytes"
+
+	// FieldDuration represents the duration of the request handling in seconds.
+	FieldDuration AccessLogField = "duration"
+
+	// FieldRemoteIP represents the IP address of the client.
+	FieldRemoteIP AccessLogField = "remote_ip"
+
+	// FieldUserAgent represents the User-Agent header of the request.
+	FieldUserAgent AccessLogField = "user_agent"
+
+	// FieldRequestID represents the request ID set by the RequestIDMiddleware.
+	FieldRequestID AccessLogField = "request_id"
+
+	// FieldTraceID represents the trace ID of the span started by the TracingMiddleware.
+	FieldTraceID AccessLogField = "trace_id"
+
+	// FieldSpanID represents the span ID of the span started by the TracingMiddleware.
+	FieldSpanID AccessLogField = "span_id"
+
+	// FieldError represents the error passed to the ctxkit log error hook.
+	FieldError AccessLogField = "error"
+)
+
+// accessLogFields represents the fields of the access log line by default.
+var accessLogFields = []AccessLogField{
+	FieldTime, FieldMethod, FieldRoute, FieldPath, FieldStatus, FieldBytes,
+	FieldDuration, FieldRemoteIP, FieldUserAgent, FieldRequestID, FieldTraceID, FieldSpanID, FieldError,
+}
+
+// AccessLogConfig represents the configuration of the AccessLogMiddleware.
+type AccessLogConfig struct {
+	writer   io.Writer
+	format   LogFormat
+	fields   []AccessLogField
+	exclude  []string
+	sampling float64
+}
+
+// AccessLogWriter represents an optional function for AccessLogMiddleware function.
+// If passed to the AccessLogMiddleware, will set the writer of the access log. By default, os.Stdout is used.
+func AccessLogWriter(w io.Writer) Option[*AccessLogConfig] {
+	return func(c *AccessLogConfig) { c.writer = w }
+}
+
+// AccessLogFormat represents an optional function for AccessLogMiddleware function.
+// If passed to the AccessLogMiddleware, will set the format of the access log. By default, LogFormatJSON is used.
+func AccessLogFormat(format LogFormat) Option[*AccessLogConfig] {
+	return func(c *AccessLogConfig) { c.format = format }
+}
+
+// AccessLogFields represents an optional function for AccessLogMiddleware function.
+// If passed to the AccessLogMiddleware, will set the fields of the access log line in the given order.
+// By default, all fields are written.
+func AccessLogFields(fields ...AccessLogField) Option[*AccessLogConfig] {
+	return func(c *AccessLogConfig) { c.fields = fields }
+}
+
+// AccessLogExclude represents an optional function for AccessLogMiddleware function.
+// If passed to the AccessLogMiddleware, will set the URL paths which requests are not logged.
+// The path ending with '*' matches by prefix, e.g. "/static/*".
+func AccessLogExclude(paths ...string) Option[*AccessLogConfig] {
+	return func(c *AccessLogConfig) { c.exclude = append(c.exclude, paths...) }
+}
+
+// AccessLogSampling represents an optional function for AccessLogMiddleware function.
+// If passed to the AccessLogMiddleware, will set the fraction of successful requests which are logged,
+// e.g. 0.1 logs every tenth request on average. Requests with HTTP 4xx and 5xx statuses are always logged.
+func AccessLogSampling(rate float64) Option[*AccessLogConfig] {
+	return func(c *AccessLogConfig) { c.sampling = rate }
+}
+
+// AccessLogMiddleware represents middleware which writes the structured access log line
+// for each request as JSON object or logfmt key=value pairs (see AccessLogFormat).
+//
+// The error passed to the ctxkit log error hook is written to the line and reported by errkit.Report.
+// Should be placed after the RequestIDMiddleware and the TracingMiddleware
+// to include the request ID and the trace and span IDs to the access log.
+func AccessLogMiddleware(options ...Option[*AccessLogConfig]) Middleware {
+	c := AccessLogConfig{
+		writer:   os.Stdout,
+		format:   LogFormatJSON,
+		fields:   accessLogFields,
+		sampling: 1,
+	}
+
+	for _, option := range options {
+		option(&c)
+	}
+
+	var mu sync.Mutex
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if c.excluded(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now().UTC()
+
+			var hookedError error
+
+			prevHook := ctxkit.GetLogErrHook(r.Context())
+
+			ctx := ctxkit.SetLogErrHook(r.Context(), func(err error) {
+				hookedError = err
+
+				if prevHook != nil {
+					prevHook(err)
+				}
+			})
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+			status := responseStatus(ww, r)
+
+			if hookedError != nil {
+				errkit.Report(hookedError)
+			}
+
+			if status < http.StatusBadRequest && c.sampling < 1 && rand.Float64() >= c.sampling { //nolint:gosec
+				return
+			}
+
+			entry := accessLogEntry{
+				request:  r,
+				start:    start,
+				duration: time.Since(start),
+				status:   status,
+				bytes:    ww.BytesWritten(),
+				err:      hookedError,
+			}
+
+			line := c.encode(&entry)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			_, _ = c.writer.Write(line)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// excluded tells whether the requests to the path are not logged.
+func (c *AccessLogConfig) excluded(path string) bool {
+	for _, p := range c.exclude {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+
+			continue
+		}
+
+		if path == p {
+			return true
+		}
+	}
+
+	return false
+}
+
+// encode encodes the access log line of the entry.
+func (c *AccessLogConfig) encode(entry *accessLogEntry) []byte {
+	var buf bytes.Buffer
+
+	if c.format == LogFormatJSON {
+		buf.WriteByte('{')
+	}
+
+	written := 0
+
+	for _, field := range c.fields {
+		value, ok := entry.value(field)
+		if !ok {
+			continue
+		}
+
+		switch c.format {
+		case LogFormatLogfmt:
+			if written > 0 {
+				buf.WriteByte(' ')
+			}
+
+			buf.WriteString(string(field))
+			buf.WriteByte('=')
+			buf.WriteString(logfmtValue(value))
+
+		default:
+			if written > 0 {
+				buf.WriteByte(',')
+			}
+
+			key, _ := json.Marshal(string(field))
+			val, _ := json.Marshal(value)
+
+			buf.Write(key)
+			buf.WriteByte(':')
+			buf.Write(val)
+		}
+
+		written++
+	}
+
+	if c.format == LogFormatJSON {
+		buf.WriteByte('}')
+	}
+
+	buf.WriteByte('\n')
+
+	return buf.Bytes()
+}
+
+// accessLogEntry represents the handled request to be written to the access log.
+type accessLogEntry struct {
+	request  *http.Request
+	start    time.Time
+	duration time.Duration
+	status   int
+	bytes    int
+	err      error
+}
+
+// value returns the value of the field. Returns false if the field has no value for the request.
+func (e *accessLogEntry) value(field AccessLogField) (any, bool) {
+	r := e.request
+
+	switch field {
+	case FieldTime:
+		return e.start.Format(time.RFC3339Nano), true
+
+	case FieldMethod:
+		return r.Method, true
+
+	case FieldRoute:
+		pattern := routePattern(r)
+		return pattern, pattern != ""
+
+	case FieldPath:
+		return r.URL.Path, true
+
+	case FieldStatus:
+		return e.status, true
+
+	case FieldBytes:
+		return e.bytes, true
+
+	case FieldDuration:
+		return e.duration.Seconds(), true
+
+	case FieldRemoteIP:
+		ip := remoteIP(r)
+		return ip, ip != ""
+
+	case FieldUserAgent:
+		return r.UserAgent(), r.UserAgent() != ""
+
+	case FieldRequestID:
+		id := ctxkit.GetRequestID(r.Context())
+		return id, id != ""
+
+	case FieldTraceID:
+		if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+			return sc.TraceID().String(), true
+		}
+
+		return nil, false
+
+	case FieldSpanID:
+		if sc := trace.SpanContextFromContext(r.Context()); sc.HasSpanID() {
+			return sc.SpanID().String(), true
+		}
+
+		return nil, false
+
+	case FieldError:
+		if e.err == nil {
+			return nil, false
+		}
+
+		return e.err.Error(), true
+
+	default:
+		return nil, false
+	}
+}
+
+// logfmtValue returns the value formatted for logfmt.
+// Strings containing spaces, quotes, '=' or control characters are quoted.
+func logfmtValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		if v == "" || strings.ContainsFunc(v, func(r rune) bool {
+			return r <= ' ' || r == '=' || r == '"' || r == '\\' || r == 0x7f
+		}) {
+			return strconv.Quote(v)
+		}
+
+		return v
+
+	case int:
+		return strconv.Itoa(v)
+
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+
+	default:
+		return strconv.Quote(fmt.Sprint(v))
+	}
+}
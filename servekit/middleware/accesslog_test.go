@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/heartwilltell/bones/ctxkit"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestAccessLogMiddleware(t *testing.T) {
+	type tcase struct {
+		options  []Option[*AccessLogConfig]
+		path     string
+		wantLine any
+	}
+
+	tests := map[string]tcase{
+		"JSON": {
+			path: "/users/42",
+			wantLine: td.Smuggle(func(line string) (map[string]any, error) {
+				var fields map[string]any
+				err := json.Unmarshal([]byte(line), &fields)
+
+				return fields, err
+			}, td.Map(map[string]any{
+				"time":       td.Re(`^\d{4}-\d{2}-\d{2}T`),
+				"method":     "GET",
+				"route":      "/users/{id}",
+				"path":       "/users/42",
+				"status":     float64(http.StatusOK),
+				"bytes":      float64(2),
+				"duration":   td.Gte(0.0),
+				"remote_ip":  "192.0.2.1",
+				"user_agent": "test agent",
+				"request_id": "req-1",
+			}, nil)),
+		},
+		"Logfmt": {
+			options: []Option[*AccessLogConfig]{
+				AccessLogFormat(LogFormatLogfmt),
+				AccessLogFields(FieldMethod, FieldRoute, FieldStatus, FieldUserAgent, FieldError),
+			},
+			path:     "/users/42",
+			wantLine: `method=GET route=/users/{id} status=200 user_agent="test agent"` + "\n",
+		},
+		"Error": {
+			options: []Option[*AccessLogConfig]{
+				AccessLogFormat(LogFormatLogfmt),
+				AccessLogFields(FieldStatus, FieldRoute, FieldError),
+				AccessLogSampling(0),
+			},
+			path:     "/fail",
+			wantLine: `status=500 route=/fail error="test error: \"quoted\""` + "\n",
+		},
+		"NotFound": {
+			options:  []Option[*AccessLogConfig]{AccessLogFormat(LogFormatLogfmt), AccessLogFields(FieldStatus, FieldRoute, FieldPath)},
+			path:     "/missing",
+			wantLine: "status=404 path=/missing\n",
+		},
+		"Excluded": {
+			options:  []Option[*AccessLogConfig]{AccessLogExclude("/health", "/users/*")},
+			path:     "/users/42",
+			wantLine: "",
+		},
+		"ExcludedExact": {
+			options:  []Option[*AccessLogConfig]{AccessLogExclude("/users")},
+			path:     "/users/42",
+			wantLine: td.Not(""),
+		},
+		"Sampled": {
+			options:  []Option[*AccessLogConfig]{AccessLogSampling(0)},
+			path:     "/users/42",
+			wantLine: "",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			router := chi.NewRouter()
+			router.Use(RequestIDMiddleware(), AccessLogMiddleware(append(tc.options, AccessLogWriter(&buf))...))
+
+			router.Get("/users/{id}", func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("OK"))
+			})
+
+			router.Get("/fail", func(w http.ResponseWriter, r *http.Request) {
+				ctxkit.GetLogErrHook(r.Context())(errors.New(`test error: "quoted"`))
+				w.WriteHeader(http.StatusInternalServerError)
+			})
+
+			r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			r.RemoteAddr = "192.0.2.1:1234"
+			r.Header.Set("User-Agent", "test agent")
+			r.Header.Set(requestIDHeader, "req-1")
+
+			router.ServeHTTP(httptest.NewRecorder(), r)
+
+			td.Cmp(t, buf.String(), tc.wantLine)
+		})
+	}
+}
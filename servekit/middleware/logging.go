@@ -8,12 +8,17 @@ import (
 	"github.com/heartwilltell/bones/ctxkit"
 	"github.com/heartwilltell/bones/errkit"
 	"github.com/heartwilltell/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // LoggingMiddleware represents logging middleware.
+// The request ID set by the RequestIDMiddleware and the trace and span IDs
+// of the span started by the TracingMiddleware are included to each log line.
 func LoggingMiddleware(log log.Logger) Middleware {
 	format := "%s %d %s Remote: %s %s"
-	errFormat := format + " Error: %s"
+	idFormat := " RequestID: %s"
+	traceFormat := " TraceID: %s SpanID: %s"
+	errFormat := " Error: %s"
 
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
@@ -21,23 +26,44 @@ func LoggingMiddleware(log log.Logger) Middleware {
 
 			var hookedError error
 
-			ctx := ctxkit.SetLogErrHook(r.Context(), func(err error) { hookedError = err })
+			prevHook := ctxkit.GetLogErrHook(r.Context())
+
+			ctx := ctxkit.SetLogErrHook(r.Context(), func(err error) {
+				hookedError = err
+
+				if prevHook != nil {
+					prevHook(err)
+				}
+			})
 
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 			next.ServeHTTP(ww, r.WithContext(ctx))
-			status := ww.Status()
+			status := responseStatus(ww, r)
+
+			lineFormat := format
+			args := []any{r.Method, status, r.RequestURI, r.RemoteAddr, time.Since(start).String()}
+
+			if id := ctxkit.GetRequestID(r.Context()); id != "" {
+				lineFormat += idFormat
+				args = append(args, id)
+			}
+
+			if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+				lineFormat += traceFormat
+				args = append(args, sc.TraceID().String(), sc.SpanID().String())
+			}
 
 			if status >= http.StatusBadRequest {
 				if hookedError != nil {
-					log.Error(errFormat, r.Method, status, r.RequestURI, r.RemoteAddr, time.Since(start).String(), hookedError)
+					log.Error(lineFormat+errFormat, append(args, hookedError)...)
 
 					errkit.Report(hookedError)
 					return
 				}
 
-				log.Error(format, r.Method, status, r.RequestURI, r.RemoteAddr, time.Since(start).String())
+				log.Error(lineFormat, args...)
 			} else {
-				log.Info(format, r.Method, status, r.RequestURI, r.RemoteAddr, time.Since(start).String())
+				log.Info(lineFormat, args...)
 			}
 		}
 
@@ -1 +1,70 @@
 package middleware
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/heartwilltell/bones/ctxkit"
+	"github.com/heartwilltell/log"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestLoggingMiddleware(t *testing.T) {
+	type tcase struct {
+		middlewares []Middleware
+		status      int
+		err         error
+		wantLine    any
+	}
+
+	tests := map[string]tcase{
+		"OK": {
+			status:   http.StatusOK,
+			wantLine: td.Re(`^INF: GET 200 /path Remote: 192\.0\.2\.1:1234 \S+\n$`),
+		},
+		"RequestID": {
+			middlewares: []Middleware{RequestIDMiddleware()},
+			status:      http.StatusOK,
+			wantLine:    td.Re(`^INF: GET 200 /path Remote: 192\.0\.2\.1:1234 \S+ RequestID: req-1\n$`),
+		},
+		"Error": {
+			middlewares: []Middleware{RequestIDMiddleware()},
+			status:      http.StatusInternalServerError,
+			err:         errors.New("test error"),
+			wantLine:    td.Re(`^ERR: GET 500 /path Remote: 192\.0\.2\.1:1234 \S+ RequestID: req-1 Error: test error\n$`),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			logger := log.New(log.WithWriter(&buf), log.WithNoColor(), log.WithNoDateTime(), log.WithLevel(log.DBG))
+
+			var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.err != nil {
+					ctxkit.GetLogErrHook(r.Context())(tc.err)
+				}
+
+				w.WriteHeader(tc.status)
+			})
+
+			handler = LoggingMiddleware(logger)(handler)
+
+			for _, mw := range tc.middlewares {
+				handler = mw(handler)
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/path", nil)
+			r.RemoteAddr = "192.0.2.1:1234"
+			r.Header.Set(requestIDHeader, "req-1")
+
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			td.Cmp(t, buf.String(), tc.wantLine)
+		})
+	}
+}
@@ -20,13 +20,15 @@ func MetricsMiddleware() Middleware {
 
 			next.ServeHTTP(ww, r)
 
+			status := responseStatus(ww, r)
+
 			httpReqDur := fmt.Sprintf(`http_request_duration{method="%s", route="%s", code="%d"}`,
-				r.Method, ctx.RoutePattern(), ww.Status(),
+				r.Method, ctx.RoutePattern(), status,
 			)
 			metrics.GetOrCreateSummaryExt(httpReqDur, 5*time.Minute, []float64{0.95, 0.99}).UpdateDuration(start)
 
 			httpReqTotal := fmt.Sprintf(`http_requests_total{method="%s", route="%s", code="%d"}`,
-				r.Method, ctx.RoutePattern(), ww.Status(),
+				r.Method, ctx.RoutePattern(), status,
 			)
 			metrics.GetOrCreateCounter(httpReqTotal).Inc()
 		}
@@ -1,6 +1,29 @@
 package middleware
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
 
 // Middleware represents an HTTP server middleware.
 type Middleware = func(next http.Handler) http.Handler
+
+// Option represents an optional function which configures the middleware.
+type Option[T any] func(o T)
+
+// responseStatus returns the status of the response written to ww.
+// If the handler has not written the status explicitly, net/http sends HTTP 200 (OK),
+// unless the connection has been hijacked to switch the protocol, e.g. to WebSocket,
+// which is reported as HTTP 101 (Switching Protocols).
+func responseStatus(ww middleware.WrapResponseWriter, r *http.Request) int {
+	if status := ww.Status(); status != 0 {
+		return status
+	}
+
+	if r.Header.Get("Upgrade") != "" {
+		return http.StatusSwitchingProtocols
+	}
+
+	return http.StatusOK
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/heartwilltell/bones/ctxkit"
+	"github.com/heartwilltell/bones/errkit"
+	"github.com/heartwilltell/bones/servekit/respond"
+)
+
+// spiffeScheme represents the URI scheme of SPIFFE ID.
+const spiffeScheme = "spiffe"
+
+// MutualTLSMiddleware represents middleware which extracts the peer identity from the verified
+// client TLS certificate and sets it to the request context (see ctxkit.GetPeerIdentity).
+//
+// Takes allowed - the list of identities allowed to pass. Each entry is prefixed by the type
+// of the certificate name it is matched against, so the name of one type never passes
+// the entry meant for another one:
+//
+//   - "subject:" - the certificate subject, e.g. "subject:CN=billing,O=Example";
+//   - "cn:" - the subject common name, e.g. "cn:billing";
+//   - "dns:" - the DNS subject alternative names, e.g. "dns:billing.svc";
+//   - "uri:" - the URI subject alternative names (including SPIFFE ID), e.g. "uri:spiffe://example.org/ns/prod/sa/billing".
+//
+// The entry ending with '*' matches by prefix, e.g. "uri:spiffe://example.org/ns/prod/*".
+// Empty list allows any verified certificate.
+//
+// Requests without verified client certificate, or with certificate not matching
+// the allowed list are rejected with errkit.ErrUnauthenticated error.
+//
+// Panics if the entry has no known type prefix.
+func MutualTLSMiddleware(allowed ...string) Middleware {
+	patterns := make([]identityPattern, 0, len(allowed))
+
+	for _, entry := range allowed {
+		pattern, err := parseIdentityPattern(entry)
+		if err != nil {
+			panic("middleware: " + err.Error())
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+				respond.Error(w, r, fmt.Errorf("%w: client certificate is not verified", errkit.ErrUnauthenticated))
+				return
+			}
+
+			identity := peerIdentity(r.TLS.VerifiedChains[0][0])
+
+			if len(patterns) > 0 && !identityAllowed(identity, patterns) {
+				respond.Error(w, r, fmt.Errorf("%w: peer %q is not allowed", errkit.ErrUnauthenticated, identity.Subject))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctxkit.SetPeerIdentity(r.Context(), identity)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// peerIdentity builds the ctxkit.PeerIdentity from the certificate.
+func peerIdentity(cert *x509.Certificate) *ctxkit.PeerIdentity {
+	identity := ctxkit.PeerIdentity{
+		Subject:    cert.Subject.String(),
+		CommonName: cert.Subject.CommonName,
+		DNSNames:   cert.DNSNames,
+		URIs:       make([]string, 0, len(cert.URIs)),
+	}
+
+	for _, uri := range cert.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+
+		if uri.Scheme == spiffeScheme && identity.SPIFFEID == "" {
+			identity.SPIFFEID = uri.String()
+		}
+	}
+
+	return &identity
+}
+
+// identityPattern represents the entry of the allowed list, matched against the names of its type only.
+type identityPattern struct {
+	kind  string
+	value string
+}
+
+// parseIdentityPattern parses the entry of the allowed list.
+func parseIdentityPattern(entry string) (identityPattern, error) {
+	kind, value, ok := strings.Cut(entry, ":")
+
+	switch {
+	case !ok || value == "":
+		return identityPattern{}, fmt.Errorf("invalid allowed identity: %q (should be prefixed by subject:, cn:, dns: or uri:)", entry)
+
+	case kind != "subject" && kind != "cn" && kind != "dns" && kind != "uri":
+		return identityPattern{}, fmt.Errorf("invalid allowed identity type: %q (should be subject, cn, dns or uri)", kind)
+
+	default:
+		return identityPattern{kind: kind, value: value}, nil
+	}
+}
+
+// names returns the identity names of the pattern type.
+func (p identityPattern) names(identity *ctxkit.PeerIdentity) []string {
+	switch p.kind {
+	case "subject":
+		return []string{identity.Subject}
+
+	case "cn":
+		return []string{identity.CommonName}
+
+	case "dns":
+		return identity.DNSNames
+
+	default:
+		return identity.URIs
+	}
+}
+
+// identityAllowed reports whether any of identity names matches the allowed patterns of its type.
+func identityAllowed(identity *ctxkit.PeerIdentity, patterns []identityPattern) bool {
+	for _, pattern := range patterns {
+		for _, name := range pattern.names(identity) {
+			if name == "" {
+				continue
+			}
+
+			if prefix, ok := strings.CutSuffix(pattern.value, "*"); ok && strings.HasPrefix(name, prefix) {
+				return true
+			}
+
+			if name == pattern.value {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/heartwilltell/bones/ctxkit"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestMutualTLSMiddleware(t *testing.T) {
+	spiffeID, _ := url.Parse("spiffe://example.org/ns/prod/sa/billing")
+
+	cert := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "billing"},
+		DNSNames: []string{"billing.svc"},
+		URIs:     []*url.URL{spiffeID},
+	}
+
+	// The certificate which names look like the names of the other types.
+	spoofed := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "spiffe://example.org/ns/prod/sa/evil"},
+		DNSNames: []string{"spiffe://example.org/ns/prod/sa/evil", "billing"},
+	}
+
+	type tcase struct {
+		allowed    []string
+		state      *tls.ConnectionState
+		wantStatus int
+		wantPeer   any
+	}
+
+	tests := map[string]tcase{
+		"NoTLS": {
+			state:      nil,
+			wantStatus: http.StatusForbidden,
+		},
+		"NotVerified": {
+			state:      &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}},
+			wantStatus: http.StatusForbidden,
+		},
+		"AnyVerified": {
+			state:      &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+			wantStatus: http.StatusOK,
+			wantPeer: &ctxkit.PeerIdentity{
+				Subject:    "CN=billing",
+				CommonName: "billing",
+				DNSNames:   []string{"billing.svc"},
+				URIs:       []string{spiffeID.String()},
+				SPIFFEID:   spiffeID.String(),
+			},
+		},
+		"AllowedBySPIFFEPrefix": {
+			allowed:    []string{"uri:spiffe://example.org/ns/prod/*"},
+			state:      &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+			wantStatus: http.StatusOK,
+			wantPeer:   td.Struct(&ctxkit.PeerIdentity{CommonName: "billing"}, nil),
+		},
+		"AllowedByDNSName": {
+			allowed:    []string{"dns:billing.svc"},
+			state:      &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+			wantStatus: http.StatusOK,
+		},
+		"AllowedByCommonName": {
+			allowed:    []string{"cn:billing"},
+			state:      &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+			wantStatus: http.StatusOK,
+		},
+		"AllowedBySubject": {
+			allowed:    []string{"subject:CN=billing"},
+			state:      &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+			wantStatus: http.StatusOK,
+		},
+		"CommonNameAsURI": {
+			allowed:    []string{"uri:spiffe://example.org/ns/prod/*"},
+			state:      &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{spoofed}}},
+			wantStatus: http.StatusForbidden,
+		},
+		"DNSNameAsCommonName": {
+			allowed:    []string{"cn:billing"},
+			state:      &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{spoofed}}},
+			wantStatus: http.StatusForbidden,
+		},
+		"CommonNameAsDNSName": {
+			allowed:    []string{"dns:billing*"},
+			state:      &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "billing"}}}}},
+			wantStatus: http.StatusForbidden,
+		},
+		"URIAsSubject": {
+			allowed:    []string{"subject:*"},
+			state:      &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{URIs: []*url.URL{spiffeID}}}}},
+			wantStatus: http.StatusForbidden,
+		},
+		"NotAllowed": {
+			allowed:    []string{"uri:spiffe://example.org/ns/dev/*", "cn:payments"},
+			state:      &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var gotPeer *ctxkit.PeerIdentity
+
+			h := MutualTLSMiddleware(tc.allowed...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPeer = ctxkit.GetPeerIdentity(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.TLS = tc.state
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			td.Cmp(t, w.Code, tc.wantStatus)
+
+			if tc.wantPeer != nil {
+				td.Cmp(t, gotPeer, tc.wantPeer)
+			}
+		})
+	}
+}
+
+func TestMutualTLSMiddleware_InvalidPattern(t *testing.T) {
+	td.CmpPanic(t, func() { MutualTLSMiddleware("spiffe://example.org/*") }, td.Contains("invalid allowed identity type"))
+	td.CmpPanic(t, func() { MutualTLSMiddleware("billing") }, td.Contains("invalid allowed identity"))
+	td.CmpPanic(t, func() { MutualTLSMiddleware("cn:") }, td.Contains("invalid allowed identity"))
+}
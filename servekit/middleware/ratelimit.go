@@ -0,0 +1,406 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/heartwilltell/bones/errkit"
+	"github.com/heartwilltell/bones/servekit/respond"
+)
+
+// rateLimitSweepInterval represents how often the MemoryLimitStore deletes expired limits.
+const rateLimitSweepInterval = time.Minute
+
+// RateLimitAlgorithm represents the algorithm of the rate limit.
+type RateLimitAlgorithm uint8
+
+const (
+	// AlgorithmTokenBucket represents the token bucket algorithm, which allows
+	// bursts of requests up to the bucket capacity, refilled at the constant rate.
+	AlgorithmTokenBucket RateLimitAlgorithm = iota
+
+	// AlgorithmSlidingWindow represents the sliding window algorithm, which limits the number
+	// of requests within the period, approximated by the counters of the current and previous windows.
+	AlgorithmSlidingWindow
+)
+
+// RateLimit represents the limit of requests per period.
+type RateLimit struct {
+	// Algorithm represents the algorithm of the rate limit.
+	Algorithm RateLimitAlgorithm
+
+	// Limit represents the number of requests allowed per Period.
+	Limit int
+
+	// Period represents the period the Limit applies to.
+	Period time.Duration
+
+	// Burst represents the capacity of the token bucket.
+	// Equals to the Limit if not set. Ignored by the sliding window.
+	Burst int
+}
+
+// TokenBucket returns the token bucket RateLimit, which allows limit requests per period
+// on average, and the burst of requests at once. The burst equals to limit if not set.
+func TokenBucket(limit int, period time.Duration, burst int) RateLimit {
+	return RateLimit{Algorithm: AlgorithmTokenBucket, Limit: limit, Period: period, Burst: burst}
+}
+
+// SlidingWindow returns the sliding window RateLimit, which allows limit requests per period.
+func SlidingWindow(limit int, period time.Duration) RateLimit {
+	return RateLimit{Algorithm: AlgorithmSlidingWindow, Limit: limit, Period: period}
+}
+
+// validate validates the rate limit.
+func (l RateLimit) validate() error {
+	if l.Limit <= 0 {
+		return fmt.Errorf("invalid rate limit: %d (should be greater than 0)", l.Limit)
+	}
+
+	if l.Period < time.Millisecond {
+		return fmt.Errorf("invalid rate limit period: %s (should be at least 1ms)", l.Period)
+	}
+
+	if l.Burst < 0 {
+		return fmt.Errorf("invalid rate limit burst: %d (should not be negative)", l.Burst)
+	}
+
+	if l.Algorithm != AlgorithmTokenBucket && l.Algorithm != AlgorithmSlidingWindow {
+		return fmt.Errorf("invalid rate limit algorithm: %d", l.Algorithm)
+	}
+
+	return nil
+}
+
+// capacity returns the capacity of the token bucket.
+func (l RateLimit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+
+	return float64(l.Limit)
+}
+
+// rate returns the number of tokens added to the token bucket per second.
+func (l RateLimit) rate() float64 { return float64(l.Limit) / l.Period.Seconds() }
+
+// RateLimitResult represents the result of the rate limit check.
+type RateLimitResult struct {
+	// Allowed tells whether the request is allowed.
+	Allowed bool
+
+	// Limit represents the number of requests allowed at once.
+	Limit int
+
+	// Remaining represents the number of requests remaining.
+	Remaining int
+
+	// Reset represents the time until the limit resets fully.
+	Reset time.Duration
+
+	// RetryAfter represents the time after which the rejected request can be retried.
+	RetryAfter time.Duration
+}
+
+// LimitStore represents the storage of the rate limits state.
+type LimitStore interface {
+	// Allow counts the request identified by the key against the limit,
+	// and tells whether the request is allowed.
+	Allow(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error)
+}
+
+// RateLimitConfig represents the configuration of the RateLimitMiddleware.
+type RateLimitConfig struct {
+	name  string
+	store LimitStore
+	key   func(r *http.Request) string
+}
+
+// RateLimitName represents an optional function for RateLimitMiddleware function.
+// If passed to the RateLimitMiddleware, will set the name of the rate limit, which separates the
+// limits sharing the store and labels the server_rate_limited_total metric. By default, "default" is used.
+func RateLimitName(name string) Option[*RateLimitConfig] {
+	return func(c *RateLimitConfig) { c.name = name }
+}
+
+// RateLimitStore represents an optional function for RateLimitMiddleware function.
+// If passed to the RateLimitMiddleware, will set the store of the rate limits state,
+// e.g. the RedisLimitStore to share the limits across the cluster. By default, MemoryLimitStore is used.
+func RateLimitStore(store LimitStore) Option[*RateLimitConfig] {
+	return func(c *RateLimitConfig) { c.store = store }
+}
+
+// RateLimitByIP represents an optional function for RateLimitMiddleware function.
+// If passed to the RateLimitMiddleware, will set the requests to be limited by the IP address
+// of the client, which is used by default. The address is taken from the http.Request RemoteAddr,
+// so the middleware should be placed after the chi RealIP middleware behind the proxy.
+func RateLimitByIP() Option[*RateLimitConfig] {
+	return func(c *RateLimitConfig) { c.key = ipKey }
+}
+
+// RateLimitByHeader represents an optional function for RateLimitMiddleware function.
+// If passed to the RateLimitMiddleware, will set the requests to be limited by the value
+// of the header, e.g. the API key. Requests without the header are limited by the IP address.
+//
+// Each new value of the header gets its own limit, so the client can bypass the limit by
+// changing the value. The middleware should be placed after the middleware which authenticates
+// the header and rejects the unknown values, or the limit by IP should be applied in addition.
+func RateLimitByHeader(header string) Option[*RateLimitConfig] {
+	return func(c *RateLimitConfig) {
+		c.key = func(r *http.Request) string {
+			if value := r.Header.Get(header); value != "" {
+				return "header:" + value
+			}
+
+			return ipKey(r)
+		}
+	}
+}
+
+// RateLimitByKey represents an optional function for RateLimitMiddleware function.
+// If passed to the RateLimitMiddleware, will set the function which returns the key
+// the requests are limited by. Requests with the empty key are not limited.
+func RateLimitByKey(key func(r *http.Request) string) Option[*RateLimitConfig] {
+	return func(c *RateLimitConfig) { c.key = key }
+}
+
+// RateLimitMiddleware represents middleware which limits the rate of requests (see TokenBucket and SlidingWindow).
+//
+// Each response has RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers.
+// Requests exceeding the limit are rejected with errkit.ErrRateLimited error, which results in
+// HTTP 429 (Too Many Requests) with Retry-After header, and counted by the server_rate_limited_total metric.
+// If the store fails, the request is allowed and the error is reported by errkit.Report.
+//
+// Panics if the limit is invalid.
+func RateLimitMiddleware(limit RateLimit, options ...Option[*RateLimitConfig]) Middleware {
+	if err := limit.validate(); err != nil {
+		panic("middleware: " + err.Error())
+	}
+
+	c := RateLimitConfig{
+		name: "default",
+		key:  ipKey,
+	}
+
+	for _, option := range options {
+		option(&c)
+	}
+
+	if c.store == nil {
+		c.store = NewMemoryLimitStore()
+	}
+
+	policy := strconv.Itoa(limit.Limit) + ";w=" + ceilSeconds(limit.Period)
+	if limit.Algorithm == AlgorithmTokenBucket {
+		policy += ";burst=" + strconv.Itoa(int(limit.capacity()))
+	}
+
+	rejected := metrics.GetOrCreateCounter(fmt.Sprintf(`server_rate_limited_total{limiter="%s"}`, c.name))
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			key := c.key(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			result, err := c.store.Allow(r.Context(), c.name+":"+key, limit)
+			if err != nil {
+				errkit.Report(fmt.Errorf("rate limit %s: %w", c.name, err))
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			h.Set("RateLimit-Reset", ceilSeconds(result.Reset))
+			h.Set("RateLimit-Policy", policy)
+
+			if !result.Allowed {
+				retryAfter := ceilSeconds(result.RetryAfter)
+
+				h.Set("Retry-After", retryAfter)
+				rejected.Inc()
+
+				respond.Error(w, r, fmt.Errorf("%w: retry after %ss", errkit.ErrRateLimited, retryAfter))
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// ipKey returns the key which limits the requests by the IP address of the client.
+func ipKey(r *http.Request) string { return "ip:" + remoteIP(r) }
+
+// ceilSeconds returns the duration rounded up to the whole seconds.
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+// tokenBucketResult returns the result of the token bucket check
+// by the number of tokens left in the bucket.
+func tokenBucketResult(limit RateLimit, allowed bool, tokens float64) RateLimitResult {
+	rate := limit.rate()
+	capacity := limit.capacity()
+
+	result := RateLimitResult{
+		Allowed:   allowed,
+		Limit:     int(capacity),
+		Remaining: int(math.Floor(tokens)),
+		Reset:     durationOf((capacity - tokens) / rate),
+	}
+
+	if !allowed {
+		result.RetryAfter = durationOf((1 - tokens) / rate)
+	}
+
+	return result
+}
+
+// slidingWindowResult returns the result of the sliding window check by the counters
+// of the current and previous windows, and the time elapsed since the current window start.
+func slidingWindowResult(limit RateLimit, allowed bool, current, previous float64, elapsed time.Duration) RateLimitResult {
+	period := float64(limit.Period)
+	estimate := previous*(1-float64(elapsed)/period) + current
+
+	result := RateLimitResult{
+		Allowed:   allowed,
+		Limit:     limit.Limit,
+		Remaining: int(math.Max(0, math.Floor(float64(limit.Limit)-estimate))),
+		Reset:     limit.Period - elapsed,
+	}
+
+	if allowed {
+		return result
+	}
+
+	// Find the time the estimate leaves the room for the request.
+	room := float64(limit.Limit - 1)
+
+	switch {
+	case current <= room && previous > 0:
+		// The room appears when the weight of the previous window decreases enough.
+		result.RetryAfter = time.Duration(period*(1-(room-current)/previous)) - elapsed
+
+	case current > room:
+		// The current window becomes the previous one, and its weight has to decrease enough.
+		result.RetryAfter = limit.Period - elapsed + time.Duration(period*(1-room/current))
+	}
+
+	if result.RetryAfter < 0 {
+		result.RetryAfter = 0
+	}
+
+	return result
+}
+
+// durationOf returns the duration of the given number of seconds.
+func durationOf(seconds float64) time.Duration { return time.Duration(seconds * float64(time.Second)) }
+
+// MemoryLimitStore represents the LimitStore which holds the rate limits state in memory of the process.
+type MemoryLimitStore struct {
+	mu      sync.Mutex
+	limits  map[string]*memoryLimit
+	sweepAt time.Time
+	now     func() time.Time
+}
+
+// memoryLimit represents the state of the rate limit held in memory.
+type memoryLimit struct {
+	// tokens and updated represent the state of the token bucket.
+	tokens  float64
+	updated time.Time
+
+	// window, current and previous represent the state of the sliding window.
+	window   int64
+	current  float64
+	previous float64
+
+	expires time.Time
+}
+
+// NewMemoryLimitStore returns a pointer to a new instance of MemoryLimitStore.
+func NewMemoryLimitStore() *MemoryLimitStore {
+	return &MemoryLimitStore{limits: make(map[string]*memoryLimit), now: time.Now}
+}
+
+// Allow implements LimitStore interface.
+func (s *MemoryLimitStore) Allow(_ context.Context, key string, limit RateLimit) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	l, ok := s.limits[key]
+	if !ok {
+		l = &memoryLimit{tokens: limit.capacity(), updated: now}
+		s.limits[key] = l
+	}
+
+	if limit.Algorithm == AlgorithmSlidingWindow {
+		period := int64(limit.Period)
+		window := now.UnixNano() / period
+		elapsed := time.Duration(now.UnixNano() - window*period)
+
+		switch l.window {
+		case window:
+		case window - 1:
+			l.previous, l.current = l.current, 0
+		default:
+			l.previous, l.current = 0, 0
+		}
+
+		l.window = window
+		l.expires = time.Unix(0, (window+2)*period)
+
+		allowed := l.previous*(1-float64(elapsed)/float64(period))+l.current+1 <= float64(limit.Limit)
+		if allowed {
+			l.current++
+		}
+
+		return slidingWindowResult(limit, allowed, l.current, l.previous, elapsed), nil
+	}
+
+	capacity := limit.capacity()
+	tokens := math.Min(capacity, l.tokens+math.Max(0, now.Sub(l.updated).Seconds())*limit.rate())
+
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+
+	l.tokens, l.updated = tokens, now
+	l.expires = now.Add(durationOf((capacity - tokens) / limit.rate()))
+
+	return tokenBucketResult(limit, allowed, tokens), nil
+}
+
+// sweep deletes the expired limits once per rateLimitSweepInterval.
+func (s *MemoryLimitStore) sweep(now time.Time) {
+	if now.Before(s.sweepAt) {
+		return
+	}
+
+	for key, l := range s.limits {
+		if !now.Before(l.expires) {
+			delete(s.limits, key)
+		}
+	}
+
+	s.sweepAt = now.Add(rateLimitSweepInterval)
+}
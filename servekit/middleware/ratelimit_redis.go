@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/heartwilltell/bones/dbkit/redisconn"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisLimitPrefix represents the prefix of the Redis keys which hold the rate limits state.
+const redisLimitPrefix = "ratelimit:"
+
+// tokenBucketScript atomically takes the token from the bucket held in the hash.
+// The time is taken from the Redis server, so the state does not depend on the clocks of the clients.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2]) / tonumber(ARGV[3])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(state[1]) or capacity
+local updated = tonumber(state[2]) or now
+
+tokens = math.min(capacity, tokens + math.max(0, now - updated) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', string.format('%.6f', tokens), 'updated', string.format('%d', now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) / rate / 1000) + 1000)
+
+return {allowed, string.format('%.6f', tokens)}
+`)
+
+// slidingWindowScript atomically counts the request in the sliding window held in the hash.
+// The time is taken from the Redis server, so the state does not depend on the clocks of the clients.
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+local window = math.floor(now / period)
+local elapsed = now - window * period
+
+local state = redis.call('HMGET', KEYS[1], 'window', 'current', 'previous')
+local stored = tonumber(state[1])
+local current = tonumber(state[2]) or 0
+local previous = tonumber(state[3]) or 0
+
+if stored == window - 1 then
+	previous, current = current, 0
+elseif stored ~= window then
+	previous, current = 0, 0
+end
+
+local allowed = 0
+if previous * (1 - elapsed / period) + current + 1 <= limit then
+	current = current + 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'window', string.format('%d', window), 'current', current, 'previous', previous)
+redis.call('PEXPIRE', KEYS[1], math.ceil((2 * period - elapsed) / 1000))
+
+return {allowed, current, previous, elapsed}
+`)
+
+// RedisLimitStore represents the LimitStore which holds the rate limits state in Redis,
+// so the limits are shared by all the processes using the same Redis.
+// Each limit is held in the single key, which makes the store compatible with Redis Cluster.
+type RedisLimitStore struct {
+	conn *redisconn.Conn
+}
+
+// NewRedisLimitStore returns a pointer to a new instance of RedisLimitStore.
+func NewRedisLimitStore(conn *redisconn.Conn) *RedisLimitStore {
+	return &RedisLimitStore{conn: conn}
+}
+
+// Allow implements LimitStore interface.
+func (s *RedisLimitStore) Allow(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error) {
+	keys := []string{redisLimitPrefix + key}
+
+	if limit.Algorithm == AlgorithmSlidingWindow {
+		values, err := slidingWindowScript.Run(ctx, s.conn, keys, limit.Limit, limit.Period.Microseconds()).Int64Slice()
+		if err != nil {
+			return RateLimitResult{}, fmt.Errorf("redis: failed to check sliding window: %w", err)
+		}
+
+		if len(values) != 4 {
+			return RateLimitResult{}, fmt.Errorf("redis: unexpected sliding window state: %v", values)
+		}
+
+		elapsed := time.Duration(values[3]) * time.Microsecond
+
+		return slidingWindowResult(limit, values[0] == 1, float64(values[1]), float64(values[2]), elapsed), nil
+	}
+
+	values, err := tokenBucketScript.Run(ctx, s.conn, keys,
+		int64(limit.capacity()), limit.Limit, limit.Period.Microseconds(),
+	).Slice()
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("redis: failed to check token bucket: %w", err)
+	}
+
+	if len(values) != 2 {
+		return RateLimitResult{}, fmt.Errorf("redis: unexpected token bucket state: %v", values)
+	}
+
+	allowed, _ := values[0].(int64)
+	tokensStr, _ := values[1].(string)
+
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("redis: unexpected token bucket state: %w", err)
+	}
+
+	return tokenBucketResult(limit, allowed == 1, tokens), nil
+}
@@ -0,0 +1,253 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/heartwilltell/bones/dbkit/redisconn"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestLimitStore_Allow(t *testing.T) {
+	// The start of the window, so the sliding window is not affected by the current time.
+	start := time.Unix(1700000000, 0)
+
+	type step struct {
+		advance time.Duration
+		want    RateLimitResult
+	}
+
+	type tcase struct {
+		limit RateLimit
+		steps []step
+	}
+
+	tests := map[string]tcase{
+		"TokenBucket": {
+			limit: TokenBucket(2, time.Second, 0),
+			steps: []step{
+				{want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 1, Reset: 500 * time.Millisecond}},
+				{want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 0, Reset: time.Second}},
+				{want: RateLimitResult{Allowed: false, Limit: 2, Remaining: 0, Reset: time.Second, RetryAfter: 500 * time.Millisecond}},
+				{advance: 250 * time.Millisecond, want: RateLimitResult{Allowed: false, Limit: 2, Remaining: 0, Reset: 750 * time.Millisecond, RetryAfter: 250 * time.Millisecond}},
+				{advance: 250 * time.Millisecond, want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 0, Reset: time.Second}},
+				{advance: 2 * time.Second, want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 1, Reset: 500 * time.Millisecond}},
+			},
+		},
+		"TokenBucketBurst": {
+			limit: TokenBucket(1, time.Second, 3),
+			steps: []step{
+				{want: RateLimitResult{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second}},
+				{want: RateLimitResult{Allowed: true, Limit: 3, Remaining: 1, Reset: 2 * time.Second}},
+				{want: RateLimitResult{Allowed: true, Limit: 3, Remaining: 0, Reset: 3 * time.Second}},
+				{want: RateLimitResult{Allowed: false, Limit: 3, Remaining: 0, Reset: 3 * time.Second, RetryAfter: time.Second}},
+			},
+		},
+		"SlidingWindow": {
+			limit: SlidingWindow(2, time.Second),
+			steps: []step{
+				{want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 1, Reset: time.Second}},
+				{want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 0, Reset: time.Second}},
+				{want: RateLimitResult{Allowed: false, Limit: 2, Remaining: 0, Reset: time.Second, RetryAfter: 1500 * time.Millisecond}},
+				// The previous window weights 0.6, which leaves no room.
+				{advance: 1400 * time.Millisecond, want: RateLimitResult{Allowed: false, Limit: 2, Remaining: 0, Reset: 600 * time.Millisecond, RetryAfter: 100 * time.Millisecond}},
+				{advance: 100 * time.Millisecond, want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 0, Reset: 500 * time.Millisecond}},
+				{advance: 2 * time.Second, want: RateLimitResult{Allowed: true, Limit: 2, Remaining: 1, Reset: 500 * time.Millisecond}},
+			},
+		},
+	}
+
+	type store struct {
+		store   LimitStore
+		setTime func(t time.Time)
+	}
+
+	stores := map[string]func(t *testing.T) store{
+		"Memory": func(*testing.T) store {
+			s := NewMemoryLimitStore()
+			return store{store: s, setTime: func(now time.Time) { s.now = func() time.Time { return now } }}
+		},
+		"Redis": func(t *testing.T) store {
+			mr := miniredis.RunT(t)
+
+			conn, err := redisconn.New(mr.Addr())
+			td.Require(t).CmpNoError(err)
+
+			t.Cleanup(func() { _ = conn.Close() })
+
+			return store{store: NewRedisLimitStore(conn), setTime: mr.SetTime}
+		},
+	}
+
+	for storeName, newStore := range stores {
+		t.Run(storeName, func(t *testing.T) {
+			for name, tc := range tests {
+				t.Run(name, func(t *testing.T) {
+					s := newStore(t)
+					now := start
+
+					for i, step := range tc.steps {
+						now = now.Add(step.advance)
+						s.setTime(now)
+
+						got, err := s.store.Allow(context.Background(), "key", tc.limit)
+						td.Require(t).CmpNoError(err)
+
+						// Redis computes the time in microseconds and stores the tokens with rounding.
+						td.Cmp(t, got, td.Struct(RateLimitResult{Allowed: step.want.Allowed, Limit: step.want.Limit, Remaining: step.want.Remaining}, td.StructFields{
+							"Reset":      td.Between(step.want.Reset-time.Millisecond, step.want.Reset+time.Millisecond),
+							"RetryAfter": td.Between(step.want.RetryAfter-time.Millisecond, step.want.RetryAfter+time.Millisecond),
+						}), "step %d", i)
+					}
+				})
+			}
+		})
+	}
+
+	t.Run("Sweep", func(t *testing.T) {
+		s := NewMemoryLimitStore()
+		now := start
+		s.now = func() time.Time { return now }
+
+		_, err := s.Allow(context.Background(), "key", TokenBucket(1, time.Second, 0))
+		td.Require(t).CmpNoError(err)
+		td.Cmp(t, s.limits, td.Len(1))
+
+		now = now.Add(2 * rateLimitSweepInterval)
+
+		_, err = s.Allow(context.Background(), "other", SlidingWindow(1, time.Second))
+		td.Require(t).CmpNoError(err)
+		td.Cmp(t, s.limits, td.Len(1))
+		td.Cmp(t, s.limits, td.ContainsKey("other"))
+	})
+}
+
+// failingStore represents the LimitStore which always fails.
+type failingStore struct{}
+
+func (failingStore) Allow(context.Context, string, RateLimit) (RateLimitResult, error) {
+	return RateLimitResult{}, errors.New("store is down")
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	type response struct {
+		status  int
+		headers map[string]any
+	}
+
+	type tcase struct {
+		limit        RateLimit
+		options      []Option[*RateLimitConfig]
+		requests     []func(r *http.Request)
+		want         []response
+		wantRejected uint64
+	}
+
+	fromIP := func(ip string) func(r *http.Request) {
+		return func(r *http.Request) { r.RemoteAddr = ip + ":1234" }
+	}
+
+	withHeader := func(value string) func(r *http.Request) {
+		return func(r *http.Request) { r.Header.Set("X-API-Key", value) }
+	}
+
+	tests := map[string]tcase{
+		"ByIP": {
+			limit:    SlidingWindow(1, time.Minute),
+			options:  []Option[*RateLimitConfig]{RateLimitName("by-ip")},
+			requests: []func(r *http.Request){fromIP("192.0.2.1"), fromIP("192.0.2.1"), fromIP("192.0.2.2")},
+			want: []response{
+				{status: http.StatusOK, headers: map[string]any{"RateLimit-Limit": "1", "RateLimit-Remaining": "0", "RateLimit-Policy": "1;w=60", "Retry-After": ""}},
+				{status: http.StatusTooManyRequests, headers: map[string]any{"RateLimit-Limit": "1", "RateLimit-Remaining": "0", "Retry-After": td.NotEmpty()}},
+				{status: http.StatusOK, headers: map[string]any{"RateLimit-Remaining": "0"}},
+			},
+			wantRejected: 1,
+		},
+		"ByHeader": {
+			limit:    TokenBucket(1, time.Minute, 2),
+			options:  []Option[*RateLimitConfig]{RateLimitName("by-header"), RateLimitByHeader("X-API-Key")},
+			requests: []func(r *http.Request){withHeader("a"), withHeader("a"), withHeader("a"), withHeader("b"), fromIP("192.0.2.1")},
+			want: []response{
+				{status: http.StatusOK, headers: map[string]any{"RateLimit-Limit": "2", "RateLimit-Remaining": "1", "RateLimit-Policy": "1;w=60;burst=2"}},
+				{status: http.StatusOK, headers: map[string]any{"RateLimit-Remaining": "0", "RateLimit-Reset": "120"}},
+				{status: http.StatusTooManyRequests, headers: map[string]any{"Retry-After": "60"}},
+				{status: http.StatusOK, headers: map[string]any{"RateLimit-Remaining": "1"}},
+				{status: http.StatusOK, headers: map[string]any{"RateLimit-Remaining": "1"}},
+			},
+			wantRejected: 1,
+		},
+		"ByKey": {
+			limit: SlidingWindow(1, time.Minute),
+			options: []Option[*RateLimitConfig]{RateLimitName("by-key"), RateLimitByKey(func(r *http.Request) string {
+				return r.Header.Get("X-API-Key")
+			})},
+			requests: []func(r *http.Request){withHeader(""), withHeader(""), withHeader("a"), withHeader("a")},
+			want: []response{
+				{status: http.StatusOK, headers: map[string]any{"RateLimit-Limit": ""}},
+				{status: http.StatusOK, headers: map[string]any{"RateLimit-Limit": ""}},
+				{status: http.StatusOK, headers: map[string]any{"RateLimit-Limit": "1"}},
+				{status: http.StatusTooManyRequests, headers: map[string]any{"RateLimit-Limit": "1"}},
+			},
+			wantRejected: 1,
+		},
+		"StoreFailed": {
+			limit:    SlidingWindow(1, time.Minute),
+			options:  []Option[*RateLimitConfig]{RateLimitName("store-failed"), RateLimitStore(failingStore{})},
+			requests: []func(r *http.Request){fromIP("192.0.2.1"), fromIP("192.0.2.1")},
+			want: []response{
+				{status: http.StatusOK, headers: map[string]any{"RateLimit-Limit": ""}},
+				{status: http.StatusOK, headers: map[string]any{"RateLimit-Limit": ""}},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler := RateLimitMiddleware(tc.limit, tc.options...)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			counter := metrics.GetOrCreateCounter(`server_rate_limited_total{limiter="` + limiterName(tc.options) + `"}`)
+			rejected := counter.Get()
+
+			for i, prepare := range tc.requests {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				prepare(r)
+
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+
+				td.Cmp(t, w.Code, tc.want[i].status, "request %d", i)
+
+				for header, value := range tc.want[i].headers {
+					td.Cmp(t, w.Header().Get(header), value, "request %d header %s", i, header)
+				}
+			}
+
+			td.Cmp(t, counter.Get()-rejected, tc.wantRejected)
+		})
+	}
+
+	t.Run("InvalidLimit", func(t *testing.T) {
+		td.CmpPanic(t, func() { RateLimitMiddleware(SlidingWindow(0, time.Second)) }, td.Contains("invalid rate limit: 0"))
+		td.CmpPanic(t, func() { RateLimitMiddleware(TokenBucket(1, time.Microsecond, 0)) }, td.Contains("invalid rate limit period"))
+		td.CmpPanic(t, func() { RateLimitMiddleware(TokenBucket(1, time.Second, -1)) }, td.Contains("invalid rate limit burst"))
+	})
+}
+
+// limiterName returns the name of the rate limit configured by the options.
+func limiterName(options []Option[*RateLimitConfig]) string {
+	var c RateLimitConfig
+
+	for _, option := range options {
+		option(&c)
+	}
+
+	return c.name
+}
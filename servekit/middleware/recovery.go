@@ -4,44 +4,77 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"runtime/debug"
 
 	"github.com/VictoriaMetrics/metrics"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/heartwilltell/bones/ctxkit"
 	"github.com/heartwilltell/bones/errkit"
 	"github.com/heartwilltell/log"
 )
 
 // RecoveryMiddleware represents middleware which catches and recovers from panics.
-// Returns the HTTP 500 (Internal Server Error) status if possible.
+//
+// The panic is logged and reported by errkit.Report along with the stack trace of the
+// goroutine (see errkit.Stack), and counted by the server_panics_total metric labeled
+// by the method and the chi route pattern. Unless the response headers have already been
+// written, the panic is responded by HTTP 500 (Internal Server Error), even if it panics with
+// the errkit error, e.g. errkit.ErrNotFound.
+//
+// The http.ErrAbortHandler panic is not recovered, since it is used to abort the response.
 func RecoveryMiddleware(log log.Logger) Middleware {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
 			defer func() {
-				if recovery := recover(); recovery != nil && isAbortHandlerError(recovery) {
-					panicErr := recoveryValueToError(recovery)
+				recovery := recover()
+				if recovery == nil {
+					return
+				}
+
+				if isAbortHandlerError(recovery) {
+					panic(recovery)
+				}
+
+				stack := debug.Stack()
+				panicErr := errkit.WithStack(recoveryValueToError(recovery), stack)
 
-					log.Error("Recovered form PANIC: %s", panicErr)
+				log.Error("Recovered from PANIC: %s\n%s", panicErr, stack)
 
-					if hook := ctxkit.GetLogErrHook(r.Context()); hook != nil {
-						hook(recoveryValueToError(recovery))
-					}
+				m := fmt.Sprintf(`server_panics_total{method="%s", route="%s"}`, r.Method, routePattern(r))
+				metrics.GetOrCreateCounter(m).Inc()
 
-					m := fmt.Sprintf(`server_panics_total{method="%s", route="%s"}`, r.Method, r.URL.Path)
-					metrics.GetOrCreateCounter(m).Inc()
+				errkit.Report(panicErr)
 
-					errkit.Report(panicErr)
+				if hook := ctxkit.GetLogErrHook(r.Context()); hook != nil {
+					hook(panicErr)
+				}
 
-					w.WriteHeader(http.StatusInternalServerError)
+				// The panic is the server fault, whatever error it panics with,
+				// so the status is not mapped from the errkit errors.
+				if ww.Status() == 0 {
+					http.Error(ww, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 				}
 			}()
 
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(ww, r)
 		}
 
 		return http.HandlerFunc(fn)
 	}
 }
 
+// routePattern returns the chi route pattern which matched the request.
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		return rctx.RoutePattern()
+	}
+
+	return ""
+}
+
 func isAbortHandlerError(recovery any) bool {
 	if recoveryErr, ok := recovery.(error); ok && errors.Is(recoveryErr, http.ErrAbortHandler) {
 		return true
@@ -1 +1,139 @@
 package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/go-chi/chi/v5"
+	"github.com/heartwilltell/bones/ctxkit"
+	"github.com/heartwilltell/bones/errkit"
+	"github.com/heartwilltell/log"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// testReporter records the errors reported by errkit.Report.
+type testReporter struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *testReporter) Report(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errs = append(r.errs, err)
+}
+
+func (r *testReporter) reset() []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	errs := r.errs
+	r.errs = nil
+
+	return errs
+}
+
+var reporter = sync.OnceValue(func() *testReporter {
+	var r testReporter
+	_ = errkit.RegisterReporter(&r)
+
+	return &r
+})
+
+func TestRecoveryMiddleware(t *testing.T) {
+	type tcase struct {
+		handler    http.HandlerFunc
+		wantStatus int
+		wantBody   string
+		wantErr    string
+	}
+
+	tests := map[string]tcase{
+		"Value": {
+			handler:    func(http.ResponseWriter, *http.Request) { panic("boom") },
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "Internal Server Error\n",
+			wantErr:    "recover value: boom",
+		},
+		"Error": {
+			handler:    func(http.ResponseWriter, *http.Request) { panic(fmt.Errorf("user: %w", errkit.ErrNotFound)) },
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "Internal Server Error\n",
+			wantErr:    "user: not found",
+		},
+		"InvalidArgument": {
+			handler:    func(http.ResponseWriter, *http.Request) { panic(errkit.ErrInvalidArgument) },
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   "Internal Server Error\n",
+			wantErr:    errkit.ErrInvalidArgument.Error(),
+		},
+		"HeadersWritten": {
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				_, _ = w.Write([]byte("partial"))
+				panic("boom")
+			},
+			wantStatus: http.StatusAccepted,
+			wantBody:   "partial",
+			wantErr:    "recover value: boom",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			reporter().reset()
+
+			var (
+				buf         bytes.Buffer
+				hookedError error
+			)
+
+			logger := log.New(log.WithWriter(&buf), log.WithNoColor(), log.WithNoDateTime())
+
+			router := chi.NewRouter()
+			router.Use(RecoveryMiddleware(logger))
+			router.Get("/panic/{name}", tc.handler)
+
+			r := httptest.NewRequest(http.MethodGet, "/panic/"+name, nil)
+			r = r.WithContext(ctxkit.SetLogErrHook(r.Context(), func(err error) { hookedError = err }))
+
+			counter := metrics.GetOrCreateCounter(`server_panics_total{method="GET", route="/panic/{name}"}`)
+			panics := counter.Get()
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			td.Cmp(t, w.Code, tc.wantStatus)
+			td.Cmp(t, w.Body.String(), tc.wantBody)
+			td.Cmp(t, counter.Get(), panics+1)
+			td.CmpString(t, hookedError, tc.wantErr)
+			td.CmpContains(t, errkit.Stack(hookedError), "runtime/debug.Stack")
+			td.CmpContains(t, buf.String(), "Recovered from PANIC: "+tc.wantErr)
+
+			errs := reporter().reset()
+			td.Require(t).Len(errs, 1)
+			td.CmpString(t, errs[0], tc.wantErr)
+			td.CmpContains(t, errkit.Stack(errs[0]), "runtime/debug.Stack")
+		})
+	}
+
+	t.Run("AbortHandler", func(t *testing.T) {
+		reporter().reset()
+
+		handler := RecoveryMiddleware(log.NewNopLog())(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic(http.ErrAbortHandler)
+		}))
+
+		td.CmpPanic(t, func() {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		}, http.ErrAbortHandler)
+
+		td.CmpEmpty(t, reporter().reset())
+	})
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/heartwilltell/bones/ctxkit"
+	"github.com/heartwilltell/bones/idkit"
+)
+
+const (
+	// requestIDHeader represents the default header which holds the request ID.
+	requestIDHeader = "X-Request-ID"
+
+	// requestIDMaxLength represents the default maximum length of the incoming request ID.
+	requestIDMaxLength = 128
+)
+
+// RequestIDConfig represents the configuration of the RequestIDMiddleware.
+type RequestIDConfig struct {
+	header    string
+	maxLength int
+	generate  func() string
+	validate  func(id string) error
+}
+
+// RequestIDHeader represents an optional function for RequestIDMiddleware function.
+// If passed to the RequestIDMiddleware, will set the header which holds the request ID.
+func RequestIDHeader(header string) Option[*RequestIDConfig] {
+	return func(c *RequestIDConfig) { c.header = http.CanonicalHeaderKey(header) }
+}
+
+// RequestIDMaxLength represents an optional function for RequestIDMiddleware function.
+// If passed to the RequestIDMiddleware, will set the maximum length of the incoming request ID.
+func RequestIDMaxLength(length int) Option[*RequestIDConfig] {
+	return func(c *RequestIDConfig) { c.maxLength = length }
+}
+
+// RequestIDGenerator represents an optional function for RequestIDMiddleware function.
+// If passed to the RequestIDMiddleware, will set the function which generates the request ID,
+// e.g. idkit.XID. By default, idkit.ULID is used.
+func RequestIDGenerator(generate func() string) Option[*RequestIDConfig] {
+	return func(c *RequestIDConfig) { c.generate = generate }
+}
+
+// RequestIDValidator represents an optional function for RequestIDMiddleware function.
+// If passed to the RequestIDMiddleware, will set the function which additionally validates
+// the incoming request ID, e.g. idkit.ValidateULID.
+func RequestIDValidator(validate func(id string) error) Option[*RequestIDConfig] {
+	return func(c *RequestIDConfig) { c.validate = validate }
+}
+
+// RequestIDMiddleware represents middleware which sets the request ID to the request
+// context (see ctxkit.GetRequestID) and to the response header.
+//
+// The request ID is taken from the X-Request-ID request header (see RequestIDHeader).
+// The incoming request ID is accepted if it is not longer than 128 characters
+// (see RequestIDMaxLength) and consists of letters, digits and '-', '_', '.', ':', '+', '/', '='
+// characters only. Otherwise, the new request ID is generated (see RequestIDGenerator).
+//
+// Should be placed before the LoggingMiddleware to include the request ID to the access log.
+func RequestIDMiddleware(options ...Option[*RequestIDConfig]) Middleware {
+	c := RequestIDConfig{
+		header:    requestIDHeader,
+		maxLength: requestIDMaxLength,
+		generate:  idkit.ULID,
+	}
+
+	for _, option := range options {
+		option(&c)
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(c.header)
+			if !c.valid(id) {
+				id = c.generate()
+				r.Header.Set(c.header, id)
+			}
+
+			w.Header().Set(c.header, id)
+
+			next.ServeHTTP(w, r.WithContext(ctxkit.SetRequestID(r.Context(), id)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// valid tells whether the incoming request ID can be accepted.
+func (c *RequestIDConfig) valid(id string) bool {
+	if id == "" || len(id) > c.maxLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if !isRequestIDChar(id[i]) {
+			return false
+		}
+	}
+
+	if c.validate != nil {
+		return c.validate(id) == nil
+	}
+
+	return true
+}
+
+// isRequestIDChar tells whether the character is allowed in the request ID.
+// Allowed characters are safe to be written to the log and to the response header as is.
+func isRequestIDChar(ch byte) bool {
+	switch {
+	case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9':
+		return true
+
+	case ch == '-', ch == '_', ch == '.', ch == ':', ch == '+', ch == '/', ch == '=':
+		return true
+
+	default:
+		return false
+	}
+}
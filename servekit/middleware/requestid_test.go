@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/heartwilltell/bones/ctxkit"
+	"github.com/heartwilltell/bones/idkit"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	ulid := td.Code(func(id string) error { return idkit.ValidateULID(id) })
+
+	type tcase struct {
+		options []Option[*RequestIDConfig]
+		header  string
+		id      string
+		wantID  any
+	}
+
+	tests := map[string]tcase{
+		"Generated":      {wantID: ulid},
+		"Accepted":       {id: "req-42:a.b/c+d=", wantID: "req-42:a.b/c+d="},
+		"InvalidChars":   {id: "req 42\n", wantID: ulid},
+		"TooLong":        {id: strings.Repeat("a", 129), wantID: ulid},
+		"MaxLength":      {options: []Option[*RequestIDConfig]{RequestIDMaxLength(4)}, id: "12345", wantID: ulid},
+		"CustomHeader":   {options: []Option[*RequestIDConfig]{RequestIDHeader("x-correlation-id")}, header: "X-Correlation-Id", id: "abc", wantID: "abc"},
+		"XIDGenerator":   {options: []Option[*RequestIDConfig]{RequestIDGenerator(idkit.XID)}, wantID: td.Re(`^[0-9A-V]{20}$`)},
+		"ValidatorFails": {options: []Option[*RequestIDConfig]{RequestIDValidator(idkit.ValidateULID)}, id: "abc", wantID: ulid},
+		"ValidatorOK":    {options: []Option[*RequestIDConfig]{RequestIDValidator(idkit.ValidateULID)}, id: "01ARZ3NDEKTSV4RRFFQ69G5FAV", wantID: "01ARZ3NDEKTSV4RRFFQ69G5FAV"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			header := tc.header
+			if header == "" {
+				header = requestIDHeader
+			}
+
+			var gotID string
+
+			handler := RequestIDMiddleware(tc.options...)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				gotID = ctxkit.GetRequestID(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.id != "" {
+				r.Header.Set(header, tc.id)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			td.Cmp(t, gotID, tc.wantID)
+			td.Cmp(t, w.Header().Get(header), gotID)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/heartwilltell/bones/ctxkit"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName represents the name of the tracer which starts the server spans.
+const tracerName = "github.com/heartwilltell/bones/servekit/middleware"
+
+// TracingConfig represents the configuration of the TracingMiddleware.
+type TracingConfig struct {
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator
+}
+
+// TracingProvider represents an optional function for TracingMiddleware function.
+// If passed to the TracingMiddleware, will set the provider of the tracer which starts the spans.
+// The spans are exported by the exporters registered in the provider, e.g. by the
+// sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter)). By default, otel.GetTracerProvider is used.
+func TracingProvider(provider trace.TracerProvider) Option[*TracingConfig] {
+	return func(c *TracingConfig) { c.provider = provider }
+}
+
+// TracingPropagator represents an optional function for TracingMiddleware function.
+// If passed to the TracingMiddleware, will set the propagator which extracts the trace context
+// from the request headers. By default, W3C Trace Context, W3C Baggage and B3 headers are extracted.
+func TracingPropagator(propagator propagation.TextMapPropagator) Option[*TracingConfig] {
+	return func(c *TracingConfig) { c.propagator = propagator }
+}
+
+// TracingMiddleware represents middleware which starts the OpenTelemetry server span for each request.
+//
+// The span continues the trace extracted from the W3C traceparent and tracestate or B3 request headers
+// (see TracingPropagator), and is named by the method and the chi route pattern, e.g. "GET /users/{id}".
+// The span records the status of the response and the error passed to the ctxkit log error hook.
+// Responses with HTTP 5xx statuses set the span status to error.
+//
+// Should be placed before the LoggingMiddleware and the AccessLogMiddleware to include
+// the trace and span IDs to the access log.
+func TracingMiddleware(options ...Option[*TracingConfig]) Middleware {
+	c := TracingConfig{
+		provider: otel.GetTracerProvider(),
+		propagator: propagation.NewCompositeTextMapPropagator(
+			b3.New(),
+			propagation.TraceContext{},
+			propagation.Baggage{},
+		),
+	}
+
+	for _, option := range options {
+		option(&c)
+	}
+
+	tracer := c.provider.Tracer(tracerName)
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := c.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			ctx, span := tracer.Start(ctx, r.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(r.Method),
+					semconv.URLPath(r.URL.Path),
+					semconv.ClientAddress(remoteIP(r)),
+				),
+			)
+			defer span.End()
+
+			if ua := r.UserAgent(); ua != "" {
+				span.SetAttributes(semconv.UserAgentOriginal(ua))
+			}
+
+			prevHook := ctxkit.GetLogErrHook(ctx)
+
+			ctx = ctxkit.SetLogErrHook(ctx, func(err error) {
+				span.RecordError(err)
+
+				if prevHook != nil {
+					prevHook(err)
+				}
+			})
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+			status := responseStatus(ww, r)
+
+			if pattern := routePattern(r); pattern != "" {
+				span.SetName(r.Method + " " + pattern)
+				span.SetAttributes(semconv.HTTPRoute(pattern))
+			}
+
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// remoteIP returns the IP address of the client.
+func remoteIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+
+	return r.RemoteAddr
+}
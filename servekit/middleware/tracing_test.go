@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/heartwilltell/bones/servekit/respond"
+	"github.com/heartwilltell/log"
+	"github.com/maxatome/go-testdeep/td"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	const (
+		traceID = "4bf92f3577b34e0ea36c2bd0d8e7d4b8"
+		spanID  = "00f067aa0ba902b7"
+	)
+
+	type tcase struct {
+		path       string
+		headers    map[string]string
+		wantName   string
+		wantParent string
+		wantStatus codes.Code
+		wantCode   int64
+		wantEvents int
+	}
+
+	tests := map[string]tcase{
+		"NoParent": {
+			path:       "/users/42",
+			wantName:   "GET /users/{id}",
+			wantStatus: codes.Unset,
+			wantCode:   http.StatusOK,
+		},
+		"W3C": {
+			path:       "/users/42",
+			headers:    map[string]string{"traceparent": "00-" + traceID + "-" + spanID + "-01"},
+			wantName:   "GET /users/{id}",
+			wantParent: spanID,
+			wantStatus: codes.Unset,
+			wantCode:   http.StatusOK,
+		},
+		"B3Single": {
+			path:       "/users/42",
+			headers:    map[string]string{"b3": traceID + "-" + spanID + "-1"},
+			wantName:   "GET /users/{id}",
+			wantParent: spanID,
+			wantStatus: codes.Unset,
+			wantCode:   http.StatusOK,
+		},
+		"B3Multi": {
+			path:       "/users/42",
+			headers:    map[string]string{"X-B3-TraceId": traceID, "X-B3-SpanId": spanID, "X-B3-Sampled": "1"},
+			wantName:   "GET /users/{id}",
+			wantParent: spanID,
+			wantStatus: codes.Unset,
+			wantCode:   http.StatusOK,
+		},
+		"Error": {
+			path:       "/fail",
+			wantName:   "GET /fail",
+			wantStatus: codes.Error,
+			wantCode:   http.StatusInternalServerError,
+			wantEvents: 1,
+		},
+		"NotFound": {
+			path:       "/missing",
+			wantName:   "GET",
+			wantStatus: codes.Unset,
+			wantCode:   http.StatusNotFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+			router := chi.NewRouter()
+			router.Use(TracingMiddleware(TracingProvider(provider)))
+
+			router.Get("/users/{id}", func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			router.Get("/fail", func(w http.ResponseWriter, r *http.Request) {
+				respond.Error(w, r, errors.New("boom"))
+			})
+
+			r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+
+			router.ServeHTTP(httptest.NewRecorder(), r)
+
+			spans := exporter.GetSpans()
+			td.Require(t).Len(spans, 1)
+
+			span := spans[0]
+			td.Cmp(t, span.Name, tc.wantName)
+			td.Cmp(t, span.SpanKind, trace.SpanKindServer)
+			td.Cmp(t, span.Status.Code, tc.wantStatus)
+			td.Cmp(t, span.Events, td.Len(tc.wantEvents))
+			td.Cmp(t, span.Attributes, td.SuperBagOf(
+				attribute.Int64("http.response.status_code", tc.wantCode),
+				attribute.String("url.path", tc.path),
+			))
+
+			if tc.wantParent != "" {
+				td.Cmp(t, span.SpanContext.TraceID().String(), traceID)
+				td.Cmp(t, span.Parent.SpanID().String(), spanID)
+				td.CmpTrue(t, span.Parent.IsRemote())
+			} else {
+				td.CmpFalse(t, span.Parent.IsValid())
+			}
+		})
+	}
+
+	t.Run("Logging", func(t *testing.T) {
+		exporter := tracetest.NewInMemoryExporter()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+		var buf bytes.Buffer
+
+		logger := log.New(log.WithWriter(&buf), log.WithNoColor(), log.WithNoDateTime())
+
+		var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			respond.Error(w, r, errors.New("boom"))
+		})
+
+		handler = LoggingMiddleware(logger)(handler)
+		handler = TracingMiddleware(TracingProvider(provider))(handler)
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("traceparent", "00-"+traceID+"-"+spanID+"-01")
+
+		handler.ServeHTTP(httptest.NewRecorder(), r)
+
+		spans := exporter.GetSpans()
+		td.Require(t).Len(spans, 1)
+
+		// The error hooked by the LoggingMiddleware is passed to the span as well.
+		td.Cmp(t, spans[0].Events, td.Len(1))
+		td.Cmp(t, buf.String(), td.Re(` TraceID: `+traceID+` SpanID: `+spans[0].SpanContext.SpanID().String()+` Error: boom\n$`))
+	})
+}
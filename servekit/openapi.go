@@ -0,0 +1,419 @@
+package servekit
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/heartwilltell/bones/errkit"
+	"github.com/heartwilltell/bones/servekit/openapi"
+	"github.com/heartwilltell/bones/servekit/respond"
+)
+
+// patternParam matches the URL parameter of the chi route pattern with an optional regexp.
+var patternParam = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?}`)
+
+// RouteDoc represents the description of the route used to generate the OpenAPI document.
+type RouteDoc struct {
+	summary     string
+	description string
+	operationID string
+	tags        []string
+	deprecated  bool
+	request     reflect.Type
+	responses   map[int]reflect.Type
+	errors      []error
+}
+
+// DocSummary represents an optional function for Handle, TypedHandler and Describe functions.
+// If passed, will set the summary of the route.
+func DocSummary(summary string) Option[*RouteDoc] {
+	return func(d *RouteDoc) { d.summary = summary }
+}
+
+// DocDescription represents an optional function for Handle, TypedHandler and Describe functions.
+// If passed, will set the description of the route.
+func DocDescription(description string) Option[*RouteDoc] {
+	return func(d *RouteDoc) { d.description = description }
+}
+
+// DocOperationID represents an optional function for Handle, TypedHandler and Describe functions.
+// If passed, will set the operation ID of the route.
+func DocOperationID(id string) Option[*RouteDoc] {
+	return func(d *RouteDoc) { d.operationID = id }
+}
+
+// DocTags represents an optional function for Handle, TypedHandler and Describe functions.
+// If passed, will add the given tags to the route.
+func DocTags(tags ...string) Option[*RouteDoc] {
+	return func(d *RouteDoc) { d.tags = append(d.tags, tags...) }
+}
+
+// DocDeprecated represents an optional function for Handle, TypedHandler and Describe functions.
+// If passed, will mark the route as deprecated.
+func DocDeprecated() Option[*RouteDoc] {
+	return func(d *RouteDoc) { d.deprecated = true }
+}
+
+// DocRequest represents an optional function for Describe function.
+// If passed, will describe the route parameters and body by the type of v,
+// which follows the same rules as the request type of Handle.
+// Typed handlers describe their request type automatically.
+func DocRequest(v any) Option[*RouteDoc] {
+	return func(d *RouteDoc) { d.request = reflect.TypeOf(v) }
+}
+
+// DocResponse represents an optional function for Handle, TypedHandler and Describe functions.
+// If passed, will describe the response with the given status by the type of v.
+// Nil v describes the response without body.
+// Typed handlers describe their successful response automatically.
+func DocResponse(status int, v any) Option[*RouteDoc] {
+	return func(d *RouteDoc) {
+		if d.responses == nil {
+			d.responses = make(map[int]reflect.Type)
+		}
+
+		d.responses[status] = reflect.TypeOf(v)
+	}
+}
+
+// DocErrors represents an optional function for Handle, TypedHandler and Describe functions.
+// If passed, will describe the error responses of the route by the given errkit errors,
+// mapped to HTTP status codes the same way as respond.Error does.
+func DocErrors(errs ...error) Option[*RouteDoc] {
+	return func(d *RouteDoc) { d.errors = append(d.errors, errs...) }
+}
+
+// Describe returns the handler h described by the given options for the OpenAPI document.
+// Use it to describe routes which are not registered by Handle.
+//
+// Example:
+//
+//	router.Method(http.MethodGet, "/files/{name}", servekit.Describe(files,
+//		servekit.DocSummary("Download the file"),
+//		servekit.DocErrors(errkit.ErrNotFound),
+//	))
+func Describe(h http.Handler, options ...Option[*RouteDoc]) http.Handler {
+	doc := &RouteDoc{}
+
+	if described, ok := h.(documented); ok {
+		*doc = *described.routeDoc()
+		doc.tags = slices.Clip(doc.tags)
+		doc.errors = slices.Clip(doc.errors)
+		doc.responses = maps.Clone(doc.responses)
+	}
+
+	for _, opt := range options {
+		opt(doc)
+	}
+
+	return &describedHandler{Handler: h, doc: doc}
+}
+
+// documented represents a handler which carries its RouteDoc.
+type documented interface {
+	routeDoc() *RouteDoc
+}
+
+// describedHandler attaches the RouteDoc to the handler.
+type describedHandler struct {
+	http.Handler
+	doc *RouteDoc
+}
+
+func (h *describedHandler) routeDoc() *RouteDoc { return h.doc }
+
+// OpenAPI returns the OpenAPI document which describes routes registered on the listener router.
+// Routes registered for any method (e.g. by chi.Router.Handle) and wildcard routes are
+// described only if their handlers are documented by Handle or Describe.
+func (l *ListenerHTTP) OpenAPI() (*openapi.Document, error) {
+	routes, err := walkRoutes(l.router)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk routes: %w", err)
+	}
+
+	// Routes registered for any method are registered for
+	// the CONNECT method as well, which is rarely done on purpose.
+	anyMethod := make(map[string]bool)
+
+	for _, rt := range routes {
+		if rt.method == http.MethodConnect {
+			anyMethod[rt.pattern] = true
+		}
+	}
+
+	doc := openapi.New(l.openAPI.title, l.openAPI.version)
+	doc.Info.Description = l.openAPI.description
+	doc.Components = &openapi.Components{}
+
+	for _, rt := range routes {
+		described, ok := rt.handler.(documented)
+		if !ok {
+			if anyMethod[rt.pattern] || strings.HasSuffix(rt.pattern, "*") {
+				continue
+			}
+
+			switch rt.method {
+			case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				continue
+			}
+		}
+
+		var routeDoc *RouteDoc
+		if ok {
+			routeDoc = described.routeDoc()
+		}
+
+		path := patternParam.ReplaceAllString(strings.TrimSuffix(rt.pattern, "/*"), "{$1}")
+		doc.AddOperation(rt.method, path, operation(doc.Components, rt.method, path, routeDoc))
+	}
+
+	if len(doc.Components.Schemas) == 0 {
+		doc.Components = nil
+	}
+
+	return doc, nil
+}
+
+// WriteOpenAPI writes the OpenAPI document returned by OpenAPI to the file by the given path.
+// The document is encoded to YAML if the file has '.yaml' or '.yml' extension, otherwise to JSON.
+func (l *ListenerHTTP) WriteOpenAPI(path string) error {
+	doc, err := l.OpenAPI()
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		data, err = doc.YAML()
+	default:
+		data, err = doc.JSON()
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to encode OpenAPI document: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil { //nolint:gosec // The document is public.
+		return fmt.Errorf("failed to write OpenAPI document: %w", err)
+	}
+
+	return nil
+}
+
+// serveOpenAPI serves the OpenAPI document as JSON, or as YAML
+// if requested by the Accept header or the 'format' query parameter.
+func (l *ListenerHTTP) serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	doc, err := l.OpenAPI()
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	if r.URL.Query().Get("format") != "yaml" && !strings.Contains(r.Header.Get("Accept"), "yaml") {
+		respond.JSON(w, r, http.StatusOK, doc)
+		return
+	}
+
+	data, err := doc.YAML()
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write(data); err != nil {
+		l.logger.Error("Failed to write OpenAPI document: %s", err)
+	}
+}
+
+// operation builds the OpenAPI operation of the route described by doc, which could be nil.
+func operation(components *openapi.Components, method, path string, doc *RouteDoc) *openapi.Operation {
+	op := &openapi.Operation{Responses: make(map[string]openapi.Response)}
+
+	if doc == nil {
+		doc = &RouteDoc{}
+	}
+
+	op.Summary = doc.summary
+	op.Description = doc.description
+	op.OperationID = doc.operationID
+	op.Tags = doc.tags
+	op.Deprecated = doc.deprecated
+
+	if doc.request != nil {
+		op.Parameters, op.RequestBody = requestDoc(components, method, doc.request)
+	}
+
+	// Each URL parameter of the path is required to be described.
+	described := make(map[string]bool)
+	for _, param := range op.Parameters {
+		if param.In == "path" {
+			described[param.Name] = true
+		}
+	}
+
+	for _, match := range patternParam.FindAllStringSubmatch(path, -1) {
+		if !described[match[1]] {
+			op.Parameters = append(op.Parameters, openapi.Parameter{
+				Name:     match[1],
+				In:       "path",
+				Required: true,
+				Schema:   &openapi.Schema{Type: "string"},
+			})
+		}
+	}
+
+	for status, t := range doc.responses {
+		resp := openapi.Response{Description: http.StatusText(status)}
+
+		if t != nil && status != http.StatusNoContent && t != reflect.TypeOf(struct{}{}) {
+			resp.Content = map[string]openapi.MediaType{
+				"application/json": {Schema: components.Schema(t)},
+			}
+		}
+
+		op.Responses[strconv.Itoa(status)] = resp
+	}
+
+	for _, err := range doc.errors {
+		status := respond.ErrorStatus(err)
+		op.Responses[strconv.Itoa(status)] = openapi.Response{Description: http.StatusText(status)}
+	}
+
+	if len(op.Responses) == 0 {
+		op.Responses["default"] = openapi.Response{Description: "Default response"}
+	}
+
+	return op
+}
+
+// requestDoc describes the parameters and the body of the request type t following the rules of Bind.
+func requestDoc(components *openapi.Components, method string, t reflect.Type) ([]openapi.Parameter, *openapi.RequestBody) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	params := requestParams(components, t)
+
+	isParam := func(field reflect.StructField) bool {
+		for _, tag := range []string{pathTag, queryTag, headerTag} {
+			if _, ok := field.Tag.Lookup(tag); ok {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if method == http.MethodGet || method == http.MethodHead {
+		return params, nil
+	}
+
+	body := components.Object(t, func(field reflect.StructField) bool { return !isParam(field) })
+	if len(body.Properties) == 0 {
+		return params, nil
+	}
+
+	return params, &openapi.RequestBody{
+		Required: true,
+		Content:  map[string]openapi.MediaType{"application/json": {Schema: body}},
+	}
+}
+
+// requestParams describes the struct fields tagged with path, query or header tags.
+func requestParams(components *openapi.Components, t reflect.Type) []openapi.Parameter {
+	params := make([]openapi.Parameter, 0)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			params = append(params, requestParams(components, field.Type)...)
+			continue
+		}
+
+		for _, in := range []string{pathTag, queryTag, headerTag} {
+			name, ok := field.Tag.Lookup(in)
+			if !ok {
+				continue
+			}
+
+			params = append(params, openapi.Parameter{
+				Name:     name,
+				In:       in,
+				Required: in == pathTag,
+				Schema:   components.Schema(field.Type),
+			})
+		}
+	}
+
+	return params
+}
+
+// typedRouteDoc returns the RouteDoc of the typed handler with the request and
+// response types described by Req and Resp unless they are set by options.
+func typedRouteDoc[Req, Resp any](options ...Option[*RouteDoc]) *RouteDoc {
+	doc := &RouteDoc{request: reflect.TypeOf((*Req)(nil)).Elem()}
+
+	for _, opt := range options {
+		opt(doc)
+	}
+
+	if len(doc.responses) == 0 {
+		var resp Resp
+
+		status := http.StatusOK
+		if coder, ok := any(resp).(StatusCoder); ok && !isNilPointer(resp) {
+			status = coder.StatusCode()
+		}
+
+		doc.responses = map[int]reflect.Type{status: reflect.TypeOf((*Resp)(nil)).Elem()}
+	}
+
+	if doc.request.Kind() == reflect.Struct && doc.request.NumField() > 0 {
+		if !hasError(doc.errors, errkit.ErrInvalidArgument) {
+			doc.errors = append(doc.errors, errkit.ErrInvalidArgument)
+		}
+	}
+
+	return doc
+}
+
+// isNilPointer reports whether v is a nil pointer, calling methods on which could panic.
+func isNilPointer(v any) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+// hasError reports whether errs contains target.
+func hasError(errs []error, target error) bool {
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,136 @@
+// Package openapi implements the model of the OpenAPI 3.1 document
+// and the generation of JSON schemas from Go types.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Version represents the version of OpenAPI specification implemented by the package.
+const Version = "3.1.0"
+
+// Document represents the root object of the OpenAPI document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components *Components         `json:"components,omitempty"`
+}
+
+// New returns a new empty Document with the given title and version of the API.
+func New(title, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: version},
+		Paths:   make(map[string]PathItem),
+	}
+}
+
+// AddOperation adds the operation for the method and the path to the document.
+func (d *Document) AddOperation(method, path string, op *Operation) {
+	item, ok := d.Paths[path]
+	if !ok {
+		item = make(PathItem)
+		d.Paths[path] = item
+	}
+
+	item.set(method, op)
+}
+
+// JSON returns the indented JSON representation of the document.
+func (d *Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// YAML returns the YAML representation of the document.
+func (d *Document) YAML() ([]byte, error) {
+	// The document is converted via JSON to reuse the json
+	// struct tags and the omitempty semantics of the model.
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode document to yaml: %w", err)
+	}
+
+	return out, nil
+}
+
+// Info represents the metadata about the API.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem represents the operations available on a single path, keyed by the lowercase HTTP method.
+type PathItem map[string]*Operation
+
+func (p PathItem) set(method string, op *Operation) {
+	switch method {
+	case "GET":
+		p["get"] = op
+	case "PUT":
+		p["put"] = op
+	case "POST":
+		p["post"] = op
+	case "DELETE":
+		p["delete"] = op
+	case "OPTIONS":
+		p["options"] = op
+	case "HEAD":
+		p["head"] = op
+	case "PATCH":
+		p["patch"] = op
+	case "TRACE":
+		p["trace"] = op
+	}
+}
+
+// Operation represents a single API operation on a path.
+type Operation struct {
+	OperationID string              `json:"operationId,omitempty"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Deprecated  bool                `json:"deprecated,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter represents a single operation parameter.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema,omitempty"`
+}
+
+// RequestBody represents the request body of the operation.
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response represents a single response of the operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType represents the schema of the content of the given media type.
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
@@ -0,0 +1,209 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	jsonMarshaler     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Components holds the reusable schemas of the document.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+
+	// names holds the schema names of the registered types.
+	names map[reflect.Type]string
+}
+
+// Schema represents the JSON schema of the data type.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Schema returns the schema of the type t as it is encoded by encoding/json.
+// Schemas of named struct types are added to the components and referenced by $ref.
+func (c *Components) Schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+
+	case t == durationType:
+		return &Schema{Type: "integer", Format: "int64"}
+
+	case t.Implements(jsonMarshaler) || reflect.PointerTo(t).Implements(jsonMarshaler):
+		// The encoding is defined by the type itself, so any value is possible.
+		return &Schema{}
+
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer", Format: "int64"}
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+
+	case reflect.String:
+		return &Schema{Type: "string"}
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		return &Schema{Type: "array", Items: c.Schema(t.Elem())}
+
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: c.Schema(t.Elem())}
+
+	case reflect.Struct:
+		if t.Name() == "" {
+			return c.Object(t, nil)
+		}
+
+		name, ok := c.names[t]
+		if !ok {
+			name = c.register(t)
+			*c.Schemas[name] = *c.Object(t, nil)
+		}
+
+		return &Schema{Ref: "#/components/schemas/" + name}
+
+	default:
+		return &Schema{}
+	}
+}
+
+// Object returns the inline object schema of the struct type t built from the
+// fields accepted by the filter. Nil filter accepts all fields. Fields of the
+// embedded structs are promoted, like encoding/json does.
+func (c *Components) Object(t reflect.Type, filter func(field reflect.StructField) bool) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	c.addFields(schema, t, filter)
+
+	return schema
+}
+
+func (c *Components) addFields(schema *Schema, t reflect.Type, filter func(field reflect.StructField) bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct {
+				c.addFields(schema, embedded, filter)
+				continue
+			}
+		}
+
+		if !field.IsExported() || (filter != nil && !filter(field)) {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = c.Schema(field.Type)
+
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// register registers the placeholder schema of the named struct type t and returns its name.
+// The placeholder is registered before the schema is built to stop the recursion on
+// self-referencing types. The name taken by the type from another package is qualified
+// by the package name, and then by the number, e.g. "User", "billing_User", "billing_User2".
+func (c *Components) register(t reflect.Type) string {
+	if c.Schemas == nil {
+		c.Schemas = make(map[string]*Schema)
+	}
+
+	if c.names == nil {
+		c.names = make(map[reflect.Type]string)
+	}
+
+	name := schemaName(t)
+
+	if _, taken := c.Schemas[name]; taken {
+		name = path.Base(t.PkgPath()) + "_" + name
+
+		for i, base := 2, name; ; i++ {
+			if _, taken = c.Schemas[name]; !taken {
+				break
+			}
+
+			name = base + strconv.Itoa(i)
+		}
+	}
+
+	c.names[t] = name
+	c.Schemas[name] = &Schema{}
+
+	return name
+}
+
+// schemaName returns the name of the component schema for the named type t.
+// Type parameters are stripped of the package paths, since they are not allowed in names.
+func schemaName(t reflect.Type) string {
+	name := t.Name()
+
+	if open := strings.IndexByte(name, '['); open >= 0 {
+		replacer := strings.NewReplacer("[", "_", "]", "", ",", "_", "*", "")
+
+		params := strings.Split(name[open+1:len(name)-1], ",")
+		for i, param := range params {
+			params[i] = param[strings.LastIndexByte(param, '.')+1:]
+		}
+
+		name = replacer.Replace(name[:open] + "[" + strings.Join(params, ",") + "]")
+	}
+
+	return name
+}
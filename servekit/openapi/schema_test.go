@@ -0,0 +1,152 @@
+package openapi
+
+import (
+	"image"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+type node struct {
+	Name     string  `json:"name"`
+	Children []*node `json:"children,omitempty"`
+}
+
+type page[T any] struct {
+	Items []T `json:"items"`
+}
+
+type embedded struct {
+	ID int64 `json:"id"`
+}
+
+type object struct {
+	embedded
+
+	Addr      netip.Addr     `json:"addr"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Data      []byte         `json:"data,omitempty"`
+	Score     *float64       `json:"score"`
+	Meta      map[string]any `json:"meta,omitempty"`
+	Ignored   string         `json:"-"`
+	Untagged  bool
+	private   string
+}
+
+// Point is named like the image.Point to test the schema names of the same-named types.
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type segment struct {
+	From   image.Point `json:"from"`
+	To     Point       `json:"to"`
+	Origin image.Point `json:"origin"`
+}
+
+func TestComponents_Schema(t *testing.T) {
+	nodeSchema := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"name":     {Type: "string"},
+			"children": {Type: "array", Items: &Schema{Ref: "#/components/schemas/node"}},
+		},
+		Required: []string{"name"},
+	}
+
+	type tcase struct {
+		t              reflect.Type
+		want           *Schema
+		wantComponents map[string]*Schema
+	}
+
+	tests := map[string]tcase{
+		"Primitive": {
+			t:    reflect.TypeOf(uint8(0)),
+			want: &Schema{Type: "integer", Format: "int32"},
+		},
+		"Anonymous": {
+			t: reflect.TypeOf(struct {
+				ID  int     `json:"id"`
+				Ptr *string `json:"ptr"`
+			}{}),
+			want: &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{"id": {Type: "integer", Format: "int64"}, "ptr": {Type: "string"}},
+				Required:   []string{"id"},
+			},
+		},
+		"Recursive": {
+			t:              reflect.TypeOf(&node{}),
+			want:           &Schema{Ref: "#/components/schemas/node"},
+			wantComponents: map[string]*Schema{"node": nodeSchema},
+		},
+		"Generic": {
+			t:    reflect.TypeOf(page[node]{}),
+			want: &Schema{Ref: "#/components/schemas/page_node"},
+			wantComponents: map[string]*Schema{
+				"page_node": {
+					Type:       "object",
+					Properties: map[string]*Schema{"items": {Type: "array", Items: &Schema{Ref: "#/components/schemas/node"}}},
+					Required:   []string{"items"},
+				},
+				"node": nodeSchema,
+			},
+		},
+		"Object": {
+			t:    reflect.TypeOf(object{}),
+			want: &Schema{Ref: "#/components/schemas/object"},
+			wantComponents: map[string]*Schema{"object": {
+				Type: "object",
+				Properties: map[string]*Schema{
+					"id":        {Type: "integer", Format: "int64"},
+					"addr":      {Type: "string"},
+					"createdAt": {Type: "string", Format: "date-time"},
+					"data":      {Type: "string", Format: "byte"},
+					"score":     {Type: "number", Format: "double"},
+					"meta":      {Type: "object", AdditionalProperties: &Schema{}},
+					"Untagged":  {Type: "boolean"},
+				},
+				Required: []string{"id", "addr", "createdAt", "Untagged"},
+			}},
+		},
+		"SameName": {
+			t:    reflect.TypeOf(segment{}),
+			want: &Schema{Ref: "#/components/schemas/segment"},
+			wantComponents: map[string]*Schema{
+				"segment": {
+					Type: "object",
+					Properties: map[string]*Schema{
+						"from":   {Ref: "#/components/schemas/Point"},
+						"to":     {Ref: "#/components/schemas/openapi_Point"},
+						"origin": {Ref: "#/components/schemas/Point"},
+					},
+					Required: []string{"from", "to", "origin"},
+				},
+				"Point": {
+					Type:       "object",
+					Properties: map[string]*Schema{"X": {Type: "integer", Format: "int64"}, "Y": {Type: "integer", Format: "int64"}},
+					Required:   []string{"X", "Y"},
+				},
+				"openapi_Point": {
+					Type:       "object",
+					Properties: map[string]*Schema{"lat": {Type: "number", Format: "double"}, "lon": {Type: "number", Format: "double"}},
+					Required:   []string{"lat", "lon"},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var components Components
+
+			td.Cmp(t, components.Schema(tc.t), tc.want)
+			td.Cmp(t, components.Schemas, tc.wantComponents)
+		})
+	}
+}
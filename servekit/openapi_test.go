@@ -0,0 +1,176 @@
+package servekit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/heartwilltell/bones/errkit"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestListenerHTTP_OpenAPI(t *testing.T) {
+	l, err := New(":0",
+		WithHealthCheck(),
+		WithProfiler(),
+		WithOpenAPI("Items", "1.2.0", OpenAPIRoute("/docs/openapi.json"), OpenAPIDescription("Items API")),
+	)
+	td.Require(t).CmpNoError(err)
+
+	api := chi.NewRouter()
+
+	Handle(api, http.MethodPut, "/items/{id:[0-9]+}", func(_ context.Context, req testRequest) (testResponse, error) {
+		return testResponse{}, nil
+	}, DocSummary("Update the item"), DocTags("items"), DocErrors(errkit.ErrNotFound))
+
+	Handle(api, http.MethodDelete, "/items/{id}", func(context.Context, struct{}) (testCreated, error) {
+		return testCreated{}, nil
+	})
+
+	api.Get("/raw/{name}", func(http.ResponseWriter, *http.Request) {})
+	api.Handle("/any", http.NotFoundHandler())
+	api.Method(http.MethodGet, "/described", Describe(http.NotFoundHandler(),
+		DocOperationID("described"),
+		DocDeprecated(),
+		DocResponse(http.StatusOK, map[string]int{}),
+	))
+
+	l.Mount("/api", api)
+
+	doc, err := l.OpenAPI()
+	td.Require(t).CmpNoError(err)
+
+	data, err := doc.JSON()
+	td.Require(t).CmpNoError(err)
+
+	td.Cmp(t, decodeJSON(t, data), decodeJSON(t, []byte(`{
+		"openapi": "3.1.0",
+		"info": {"title": "Items", "version": "1.2.0", "description": "Items API"},
+		"paths": {
+			"/api/items/{id}": {
+				"put": {
+					"summary": "Update the item",
+					"tags": ["items"],
+					"parameters": [
+						{"name": "id", "in": "path", "required": true, "schema": {"type": "integer", "format": "int64"}},
+						{"name": "tag", "in": "query", "schema": {"type": "array", "items": {"type": "string"}}},
+						{"name": "timeout", "in": "query", "schema": {"type": "integer", "format": "int64"}},
+						{"name": "X-Token", "in": "header", "schema": {"type": "string"}}
+					],
+					"requestBody": {
+						"required": true,
+						"content": {"application/json": {"schema": {
+							"type": "object",
+							"properties": {"name": {"type": "string"}},
+							"required": ["name"]
+						}}}
+					},
+					"responses": {
+						"200": {
+							"description": "OK",
+							"content": {"application/json": {"schema": {"$ref": "#/components/schemas/testResponse"}}}
+						},
+						"400": {"description": "Bad Request"},
+						"404": {"description": "Not Found"}
+					}
+				},
+				"delete": {
+					"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"responses": {"204": {"description": "No Content"}}
+				}
+			},
+			"/api/raw/{name}": {
+				"get": {
+					"parameters": [{"name": "name", "in": "path", "required": true, "schema": {"type": "string"}}],
+					"responses": {"default": {"description": "Default response"}}
+				}
+			},
+			"/api/described": {
+				"get": {
+					"operationId": "described",
+					"deprecated": true,
+					"responses": {"200": {
+						"description": "OK",
+						"content": {"application/json": {"schema": {
+							"type": "object",
+							"additionalProperties": {"type": "integer", "format": "int64"}
+						}}}
+					}}
+				}
+			},
+			"/health": {"get": {"responses": {"default": {"description": "Default response"}}}},
+			"/livez": {"get": {"responses": {"default": {"description": "Default response"}}}},
+			"/readyz": {"get": {"responses": {"default": {"description": "Default response"}}}},
+			"/docs/openapi.json": {"get": {"responses": {"default": {"description": "Default response"}}}}
+		},
+		"components": {"schemas": {"testResponse": {
+			"type": "object",
+			"properties": {
+				"id": {"type": "integer", "format": "int64"},
+				"tags": {"type": "array", "items": {"type": "string"}},
+				"timeout": {"type": "string"},
+				"token": {"type": "string"},
+				"name": {"type": "string"}
+			},
+			"required": ["id", "tags", "timeout", "token", "name"]
+		}}}
+	}`)))
+
+	t.Run("Endpoint", func(t *testing.T) {
+		do := func(target, accept string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest(http.MethodGet, target, nil)
+			r.Header.Set("Accept", accept)
+
+			w := httptest.NewRecorder()
+			l.server.Handler.ServeHTTP(w, r)
+
+			return w
+		}
+
+		resp := do("/docs/openapi.json", "application/json")
+		td.Cmp(t, resp.Code, http.StatusOK)
+		td.Cmp(t, resp.Header().Get("Content-Type"), "application/json; charset=utf-8")
+		td.Cmp(t, decodeJSON(t, resp.Body.Bytes()), decodeJSON(t, data))
+
+		resp = do("/docs/openapi.json", "application/yaml")
+		td.Cmp(t, resp.Header().Get("Content-Type"), "application/yaml; charset=utf-8")
+		td.CmpContains(t, resp.Body.String(), "openapi: 3.1.0")
+
+		resp = do("/docs/openapi.json?format=yaml", "")
+		td.CmpContains(t, resp.Body.String(), "title: Items")
+	})
+
+	t.Run("WriteOpenAPI", func(t *testing.T) {
+		dir := t.TempDir()
+
+		td.Require(t).CmpNoError(l.WriteOpenAPI(filepath.Join(dir, "openapi.json")))
+		written, err := os.ReadFile(filepath.Join(dir, "openapi.json"))
+		td.Require(t).CmpNoError(err)
+		td.Cmp(t, decodeJSON(t, written), decodeJSON(t, data))
+
+		td.Require(t).CmpNoError(l.WriteOpenAPI(filepath.Join(dir, "openapi.yaml")))
+		written, err = os.ReadFile(filepath.Join(dir, "openapi.yaml"))
+		td.Require(t).CmpNoError(err)
+		td.CmpContains(t, string(written), "openapi: 3.1.0")
+	})
+}
+
+func TestWithOpenAPI_InvalidRoute(t *testing.T) {
+	_, err := New(":0", WithOpenAPI("API", "1.0.0", OpenAPIRoute("openapi.json")))
+	td.CmpContains(t, err, "invalid OpenAPI route: openapi.json (route should start with '/' slash)")
+}
+
+// decodeJSON decodes data to compare documents which contain '$' signs treated by td.JSON as placeholders.
+func decodeJSON(t *testing.T, data []byte) any {
+	t.Helper()
+
+	var v any
+	td.Require(t).CmpNoError(json.Unmarshal(data, &v))
+
+	return v
+}
@@ -1,6 +1,8 @@
 package servekit
 
 import (
+	"crypto/tls"
+	"io/fs"
 	"time"
 
 	"github.com/heartwilltell/hc"
@@ -15,6 +17,10 @@ import (
 // Option functions should only be passed to ListenerHTTP constructor function New.
 type Option[T any] func(o T)
 
+// ListenerOption represents an option of ListenerHTTP constructor function New.
+// It allows other packages to hold and pass the options around, e.g. servetest.New.
+type ListenerOption = Option[*config]
+
 // WithReadTimeout sets the http.Server ReadTimeout.
 func WithReadTimeout(t time.Duration) Option[*config] {
 	return func(c *config) { c.readTimeout = t }
@@ -30,12 +36,163 @@ func WithIdleTimeout(t time.Duration) Option[*config] {
 	return func(s *config) { s.idleTimeout = t }
 }
 
+// WithShutdownTimeout sets the time given to the listener
+// to drain the active connections during the graceful shutdown.
+// The shutdown hooks get the same amount of time to finish.
+func WithShutdownTimeout(t time.Duration) Option[*config] {
+	return func(c *config) { c.shutdown.timeout = t }
+}
+
+// WithShutdownDrain sets the period between the shutdown signal and the actual
+// shutdown, during which the listener keeps serving requests, but the readiness
+// endpoint reports failure, so load balancers stop routing the traffic to it.
+func WithShutdownDrain(d time.Duration) Option[*config] {
+	return func(c *config) { c.shutdown.drain = d }
+}
+
+// WithOnShutdown adds the given hooks which are executed in order after the listener
+// is drained, e.g. to close database pools or flush error reports. The hooks are executed
+// as well when the listener fails to start serving, e.g. with ErrBindFailed.
+// Errors returned by hooks are aggregated and returned from Serve.
+func WithOnShutdown(hooks ...ShutdownHook) Option[*config] {
+	return func(c *config) { c.shutdown.hooks = append(c.shutdown.hooks, hooks...) }
+}
+
 // WithGlobalMiddlewares sets given middlewares as router-wide middlewares.
 // Means that they will be applied to each server endpoint.
 func WithGlobalMiddlewares(m ...Middleware) Option[*config] {
 	return func(c *config) { c.globalMiddlewares = append(c.globalMiddlewares, m...) }
 }
 
+// WithSocketActivation tells the listener to serve on the socket passed to the process
+// by systemd socket activation (LISTEN_FDS) instead of binding to the listener address.
+// The name selects the socket by its FileDescriptorName, empty name selects the first socket.
+func WithSocketActivation(name string) Option[*config] {
+	return func(c *config) {
+		c.socket.activation = true
+		c.socket.activationName = name
+	}
+}
+
+// WithUnixSocketMode sets file permissions of the unix domain socket
+// when the listener address has the 'unix:' prefix, e.g. "unix:/run/app.sock".
+func WithUnixSocketMode(mode fs.FileMode) Option[*config] {
+	return func(c *config) { c.socket.unixMode = mode }
+}
+
+// WithTLSReload turns on the automatic reload of the TLS certificate used by ServeTLS.
+// The certificate and key files are polled for changes, and the new keypair
+// is swapped in without the listener restart. An invalid keypair is refused.
+// Receives the following option to configure the reload:
+// - TLSReloadInterval - to set the files polling interval.
+func WithTLSReload(options ...Option[*TLSReloadConfig]) Option[*config] {
+	return func(c *config) {
+		c.tlsReload.enable = true
+
+		for _, opt := range options {
+			opt(&c.tlsReload)
+		}
+	}
+}
+
+// TLSReloadInterval represents an optional function for WithTLSReload function.
+// If passed to the WithTLSReload, will set the config.tlsReload.interval.
+func TLSReloadInterval(interval time.Duration) Option[*TLSReloadConfig] {
+	return func(c *TLSReloadConfig) { c.interval = interval }
+}
+
+// WithMutualTLS turns on the mutual TLS for ServeTLS. Client certificates
+// are verified against CA certificates from the given PEM encoded caFile bundle.
+// Use middleware.MutualTLSMiddleware to access the verified peer identity.
+// Receives the following option to configure the mutual TLS:
+// - MutualTLSClientAuth - to change the client certificate verification policy.
+func WithMutualTLS(caFile string, options ...Option[*MutualTLSConfig]) Option[*config] {
+	return func(c *config) {
+		c.mutualTLS.enable = true
+		c.mutualTLS.caFile = caFile
+
+		for _, opt := range options {
+			opt(&c.mutualTLS)
+		}
+	}
+}
+
+// MutualTLSClientAuth represents an optional function for WithMutualTLS function.
+// If passed to the WithMutualTLS, will set the config.mutualTLS.clientAuth.
+func MutualTLSClientAuth(clientAuth tls.ClientAuthType) Option[*MutualTLSConfig] {
+	return func(c *MutualTLSConfig) { c.clientAuth = clientAuth }
+}
+
+// WithAdminListener turns on the separate internal listener on the given addr,
+// which serves builtin endpoints (health, metrics, profiler) instead of the main one.
+// The admin listener is served and shut down together with the main listener.
+// Note that middlewares set by WithGlobalMiddlewares are not applied to the admin listener.
+func WithAdminListener(addr string) Option[*config] {
+	return func(c *config) { c.adminAddr = addr }
+}
+
+// WithH2C turns on HTTP/2 over cleartext TCP (h2c) for Serve and ServeListener,
+// e.g. to serve gRPC-gateway style services behind an L7 proxy.
+func WithH2C() Option[*config] {
+	return func(c *config) { c.h2c = true }
+}
+
+// WithHTTP3 turns on the HTTP/3 (QUIC) listener which serves the same router
+// alongside ServeTLS, and announces the HTTP/3 support with Alt-Svc header.
+// Receives the following option to configure the HTTP/3 listener:
+// - HTTP3Addr - to set the UDP address, which is the listener address by default.
+func WithHTTP3(options ...Option[*HTTP3Config]) Option[*config] {
+	return func(c *config) {
+		c.http3.enable = true
+
+		for _, opt := range options {
+			opt(&c.http3)
+		}
+	}
+}
+
+// HTTP3Addr represents an optional function for WithHTTP3 function.
+// If passed to the WithHTTP3, will set the config.http3.addr.
+func HTTP3Addr(addr string) Option[*HTTP3Config] {
+	return func(c *HTTP3Config) { c.addr = addr }
+}
+
+// WithUpgrade turns on the zero-downtime binary upgrade. On SIGUSR2 the listener starts
+// the new process of the binary and hands off the bound listeners to it. When the new
+// process starts serving, the listener shuts down the same way as when the ctx passed
+// to Serve is canceled, so Serve returns and the old process is expected to exit.
+// If the new process fails to start serving in time, it is killed and the listener
+// keeps serving. The upgrade is supported on unix systems only, and is not compatible
+// with HTTP/3. Only one listener per process should turn on the upgrade.
+// Receives the following options to configure the upgrade:
+// - UpgradeCommand - to set the binary and arguments of the new process.
+// - UpgradeTimeout - to set the time given to the new process to start serving.
+func WithUpgrade(options ...Option[*UpgradeConfig]) Option[*config] {
+	return func(c *config) {
+		c.upgrade.enable = true
+
+		for _, opt := range options {
+			opt(&c.upgrade)
+		}
+	}
+}
+
+// UpgradeCommand represents an optional function for WithUpgrade function.
+// If passed to the WithUpgrade, will set the config.upgrade.binary and config.upgrade.args.
+// By default, the executable of the current process is started with the same arguments.
+func UpgradeCommand(binary string, args ...string) Option[*UpgradeConfig] {
+	return func(c *UpgradeConfig) {
+		c.binary = binary
+		c.args = args
+	}
+}
+
+// UpgradeTimeout represents an optional function for WithUpgrade function.
+// If passed to the WithUpgrade, will set the config.upgrade.timeout.
+func UpgradeTimeout(timeout time.Duration) Option[*UpgradeConfig] {
+	return func(c *UpgradeConfig) { c.timeout = timeout }
+}
+
 // WithLogger sets the server logger.
 func WithLogger(l log.Logger) Option[*config] {
 	return func(c *config) {
@@ -45,10 +202,17 @@ func WithLogger(l log.Logger) Option[*config] {
 	}
 }
 
-// WithHealthCheck turns on the health check endpoint.
-// Receives the following option to configure the endpoint:
+// WithHealthCheck turns on the health check, liveness and readiness endpoints.
+// The readiness endpoint reports failure until the listener starts serving
+// and as soon as the shutdown begins, or when any of the dependencies is unhealthy.
+// Receives the following option to configure the endpoints:
 // - SetHealthChecker - to change the healthChecker implementation.
 // - HealthCheckRoute - to set the endpoint route.
+// - HealthCheckLivenessRoute - to set the liveness endpoint route.
+// - HealthCheckReadinessRoute - to set the readiness endpoint route.
+// - HealthCheckDependency - to add the named dependency checked by readiness endpoint.
+// - HealthCheckTimeout - to set the timeout of dependencies checks.
+// - HealthCheckVerbose - to respond with JSON report by default.
 // - HealthCheckAccessLog - to enable access log for endpoint.
 // - HealthCheckMetricsForEndpoint - to enable metrics collection for endpoint.
 func WithHealthCheck(options ...Option[*HealthEndpointConfig]) Option[*config] {
@@ -80,6 +244,47 @@ func HealthCheckRoute(route string) Option[*HealthEndpointConfig] {
 	return func(c *HealthEndpointConfig) { c.route = route }
 }
 
+// HealthCheckLivenessRoute represents an optional function for WithHealthCheck function.
+// If passed to the WithHealthCheck, will set the config.health.livenessRoute.
+func HealthCheckLivenessRoute(route string) Option[*HealthEndpointConfig] {
+	return func(c *HealthEndpointConfig) { c.livenessRoute = route }
+}
+
+// HealthCheckReadinessRoute represents an optional function for WithHealthCheck function.
+// If passed to the WithHealthCheck, will set the config.health.readinessRoute.
+func HealthCheckReadinessRoute(route string) Option[*HealthEndpointConfig] {
+	return func(c *HealthEndpointConfig) { c.readinessRoute = route }
+}
+
+// HealthCheckDependency represents an optional function for WithHealthCheck function.
+// If passed to the WithHealthCheck, will add the named checker to the config.health.dependencies.
+// Use HealthCheckFunc to adapt connections which have no Health method,
+// e.g. HealthCheckDependency("redis", HealthCheckFunc(redisConn.HealthCheck)).
+func HealthCheckDependency(name string, checker hc.HealthChecker) Option[*HealthEndpointConfig] {
+	return func(c *HealthEndpointConfig) {
+		// To not shoot in the leg.
+		if checker == nil {
+			return
+		}
+
+		c.dependencies = append(c.dependencies, &dependency{name: name, checker: checker})
+	}
+}
+
+// HealthCheckTimeout represents an optional function for WithHealthCheck function.
+// If passed to the WithHealthCheck, will set the config.health.timeout.
+func HealthCheckTimeout(timeout time.Duration) Option[*HealthEndpointConfig] {
+	return func(c *HealthEndpointConfig) { c.timeout = timeout }
+}
+
+// HealthCheckVerbose represents an optional function for WithHealthCheck function.
+// If passed to the WithHealthCheck, will set the config.health.verbose, which makes liveness
+// and readiness endpoints respond with JSON report. Regardless of the option, the report
+// can be requested with 'verbose' query parameter, e.g. '/readyz?verbose'.
+func HealthCheckVerbose(enable bool) Option[*HealthEndpointConfig] {
+	return func(c *HealthEndpointConfig) { c.verbose = enable }
+}
+
 // HealthCheckAccessLog represents an optional function for WithHealthCheck function.
 // If passed to the WithHealthCheck, will set the config.health.accessLogsEnabled to true.
 func HealthCheckAccessLog(enable bool) Option[*HealthEndpointConfig] {
@@ -126,17 +331,163 @@ func MetricsMetricsForEndpoint(enable bool) Option[*MetricsEndpointConfig] {
 }
 
 // WithProfiler turns on the profiler endpoint.
-func WithProfiler(cfg ProfilerEndpointConfig) Option[*config] {
+// Receives the following option to configure the endpoint:
+// - ProfilerRoute - to set the endpoint route.
+// - ProfilerAccessLog - to enable access log for endpoint.
+// - ProfilerGuard - to protect the endpoint by middlewares, e.g. authentication.
+// - ProfilerBlockRate - to set the block profiling rate.
+// - ProfilerMutexFraction - to set the mutex profiling fraction.
+func WithProfiler(options ...Option[*ProfilerEndpointConfig]) Option[*config] {
 	return func(c *config) {
 		c.profiler.enable = true
-		c.profiler.accessLogsEnabled = cfg.accessLogsEnabled
 
-		if cfg.route != "" {
-			c.profiler.route = cfg.route
+		for _, opt := range options {
+			opt(&c.profiler)
+		}
+	}
+}
+
+// ProfilerRoute represents an optional function for WithProfiler function.
+// If passed to the WithProfiler, will set the config.profiler.route.
+func ProfilerRoute(route string) Option[*ProfilerEndpointConfig] {
+	return func(c *ProfilerEndpointConfig) { c.route = route }
+}
+
+// ProfilerAccessLog represents an optional function for WithProfiler function.
+// If passed to the WithProfiler, will set the config.profiler.accessLogsEnabled to true.
+func ProfilerAccessLog(enable bool) Option[*ProfilerEndpointConfig] {
+	return func(c *ProfilerEndpointConfig) { c.accessLogsEnabled = enable }
+}
+
+// ProfilerGuard represents an optional function for WithProfiler function.
+// If passed to the WithProfiler, will add the given middlewares to the config.profiler.guards,
+// which are applied to each profiler route, e.g. to authenticate the request.
+func ProfilerGuard(guards ...Middleware) Option[*ProfilerEndpointConfig] {
+	return func(c *ProfilerEndpointConfig) { c.guards = append(c.guards, guards...) }
+}
+
+// ProfilerBlockRate represents an optional function for WithProfiler function.
+// If passed to the WithProfiler, will set the config.profiler.blockRate,
+// which is passed to runtime.SetBlockProfileRate. Zero turns off the block profiling.
+// The rate can be changed at runtime via the '{route}/rates' endpoint.
+func ProfilerBlockRate(rate int) Option[*ProfilerEndpointConfig] {
+	return func(c *ProfilerEndpointConfig) { c.blockRate = rate }
+}
+
+// ProfilerMutexFraction represents an optional function for WithProfiler function.
+// If passed to the WithProfiler, will set the config.profiler.mutexFraction,
+// which is passed to runtime.SetMutexProfileFraction. Zero turns off the mutex profiling.
+// The fraction can be changed at runtime via the '{route}/rates' endpoint.
+func ProfilerMutexFraction(rate int) Option[*ProfilerEndpointConfig] {
+	return func(c *ProfilerEndpointConfig) { c.mutexFraction = rate }
+}
+
+// WithRoutes turns on the endpoint which lists the routes served by the listener.
+// See ListenerHTTP.Routes. The list is served as a table, or as JSON if the
+// 'format=json' query parameter is passed.
+// Receives the following option to configure the endpoint:
+// - RoutesRoute - to set the endpoint route.
+// - RoutesAccessLog - to enable access log for endpoint.
+// - RoutesGuard - to protect the endpoint by middlewares, e.g. authentication.
+func WithRoutes(options ...Option[*RoutesEndpointConfig]) Option[*config] {
+	return func(c *config) {
+		c.routes.enable = true
+
+		for _, opt := range options {
+			opt(&c.routes)
+		}
+	}
+}
+
+// RoutesRoute represents an optional function for WithRoutes function.
+// If passed to the WithRoutes, will set the config.routes.route.
+func RoutesRoute(route string) Option[*RoutesEndpointConfig] {
+	return func(c *RoutesEndpointConfig) { c.route = route }
+}
+
+// RoutesAccessLog represents an optional function for WithRoutes function.
+// If passed to the WithRoutes, will set the config.routes.accessLogsEnabled to true.
+func RoutesAccessLog(enable bool) Option[*RoutesEndpointConfig] {
+	return func(c *RoutesEndpointConfig) { c.accessLogsEnabled = enable }
+}
+
+// RoutesGuard represents an optional function for WithRoutes function.
+// If passed to the WithRoutes, will add the given middlewares to the config.routes.guards,
+// which are applied to the endpoint, e.g. to authenticate the request.
+func RoutesGuard(guards ...Middleware) Option[*RoutesEndpointConfig] {
+	return func(c *RoutesEndpointConfig) { c.guards = append(c.guards, guards...) }
+}
+
+// WithOpenAPI turns on the endpoint which serves the OpenAPI document
+// describing the routes of the listener. See ListenerHTTP.OpenAPI.
+// Receives the following option to configure the endpoint:
+// - OpenAPIRoute - to set the endpoint route.
+// - OpenAPIDescription - to set the description of the API.
+// - OpenAPIAccessLog - to enable access log for endpoint.
+func WithOpenAPI(title, version string, options ...Option[*OpenAPIEndpointConfig]) Option[*config] {
+	return func(c *config) {
+		c.openAPI.enable = true
+		c.openAPI.title = title
+		c.openAPI.version = version
+
+		for _, opt := range options {
+			opt(&c.openAPI)
 		}
 	}
 }
 
+// OpenAPIRoute represents an optional function for WithOpenAPI function.
+// If passed to the WithOpenAPI, will set the config.openAPI.route.
+func OpenAPIRoute(route string) Option[*OpenAPIEndpointConfig] {
+	return func(c *OpenAPIEndpointConfig) { c.route = route }
+}
+
+// OpenAPIDescription represents an optional function for WithOpenAPI function.
+// If passed to the WithOpenAPI, will set the config.openAPI.description.
+func OpenAPIDescription(description string) Option[*OpenAPIEndpointConfig] {
+	return func(c *OpenAPIEndpointConfig) { c.description = description }
+}
+
+// OpenAPIAccessLog represents an optional function for WithOpenAPI function.
+// If passed to the WithOpenAPI, will set the config.openAPI.accessLogsEnabled to true.
+func OpenAPIAccessLog(enable bool) Option[*OpenAPIEndpointConfig] {
+	return func(c *OpenAPIEndpointConfig) { c.accessLogsEnabled = enable }
+}
+
+// HTTP3Config represents configuration of HTTP/3 listener.
+type HTTP3Config struct {
+	addr   string
+	enable bool
+}
+
+// ShutdownConfig represents configuration of the graceful shutdown.
+type ShutdownConfig struct {
+	timeout time.Duration
+	drain   time.Duration
+	hooks   []ShutdownHook
+}
+
+// TLSReloadConfig represents configuration of TLS certificate reload.
+type TLSReloadConfig struct {
+	interval time.Duration
+	enable   bool
+}
+
+// UpgradeConfig represents configuration of the zero-downtime binary upgrade.
+type UpgradeConfig struct {
+	binary  string
+	args    []string
+	timeout time.Duration
+	enable  bool
+}
+
+// MutualTLSConfig represents configuration of mutual TLS.
+type MutualTLSConfig struct {
+	caFile     string
+	clientAuth tls.ClientAuthType
+	enable     bool
+}
+
 // MetricsEndpointConfig represents configuration of builtin metrics route.
 type MetricsEndpointConfig struct {
 	route                     string
@@ -148,7 +499,12 @@ type MetricsEndpointConfig struct {
 // HealthEndpointConfig represents configuration of builtin health check route.
 type HealthEndpointConfig struct {
 	route                     string
+	livenessRoute             string
+	readinessRoute            string
 	healthChecker             hc.HealthChecker
+	dependencies              []*dependency
+	timeout                   time.Duration
+	verbose                   bool
 	accessLogsEnabled         bool
 	metricsForEndpointEnabled bool
 	enable                    bool
@@ -157,6 +513,27 @@ type HealthEndpointConfig struct {
 // ProfilerEndpointConfig represents configuration of builtin profiler route.
 type ProfilerEndpointConfig struct {
 	route             string
+	guards            []Middleware
+	blockRate         int
+	mutexFraction     int
+	accessLogsEnabled bool
+	enable            bool
+}
+
+// RoutesEndpointConfig represents configuration of builtin routes listing route.
+type RoutesEndpointConfig struct {
+	route             string
+	guards            []Middleware
+	accessLogsEnabled bool
+	enable            bool
+}
+
+// OpenAPIEndpointConfig represents configuration of builtin OpenAPI document route.
+type OpenAPIEndpointConfig struct {
+	route             string
+	title             string
+	version           string
+	description       string
 	accessLogsEnabled bool
 	enable            bool
 }
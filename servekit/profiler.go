@@ -0,0 +1,124 @@
+package servekit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/heartwilltell/bones/errkit"
+	"github.com/heartwilltell/bones/servekit/respond"
+)
+
+// profiles represents the names of runtime/pprof profiles served by the profiler endpoint.
+var profiles = []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"}
+
+// blockProfileRate holds the last rate passed to runtime.SetBlockProfileRate,
+// since the runtime does not provide a way to read it back.
+var blockProfileRate atomic.Int64
+
+// ProfilerRates represents the block and mutex profiling rates.
+type ProfilerRates struct {
+	// BlockRate represents the runtime.SetBlockProfileRate rate.
+	BlockRate int `json:"blockRate"`
+
+	// MutexFraction represents the runtime.SetMutexProfileFraction rate.
+	MutexFraction int `json:"mutexFraction"`
+}
+
+// profilerRouter returns the router which serves the full set of pprof handlers.
+func profilerRouter() chi.Router {
+	profiler := chi.NewRouter()
+
+	profiler.HandleFunc("/", profilerIndex)
+	profiler.HandleFunc("/cmdline", pprof.Cmdline)
+	profiler.HandleFunc("/profile", pprof.Profile)
+	profiler.HandleFunc("/symbol", pprof.Symbol)
+	profiler.HandleFunc("/trace", pprof.Trace)
+	profiler.HandleFunc("/rates", profilerRates)
+
+	for _, name := range profiles {
+		profiler.Handle("/"+name, pprof.Handler(name))
+	}
+
+	return profiler
+}
+
+// profilerIndex serves the pprof index page. Redirects to the route with trailing slash,
+// since the links on the index page are relative to the profiler route.
+func profilerIndex(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasSuffix(r.URL.Path, "/") {
+		http.Redirect(w, r, r.URL.Path+"/", http.StatusMovedPermanently)
+		return
+	}
+
+	pprof.Index(w, r)
+}
+
+// profilerRates responds with the current block and mutex profiling rates.
+// The rates can be changed at runtime by POST request with
+// 'block' and 'mutex' query parameters, e.g. POST /rates?block=1&mutex=5.
+func profilerRates(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+
+	case http.MethodPost:
+		query := r.URL.Query()
+
+		// Both rates are validated before any of them is changed.
+		blockRate, err := parseProfilerRate(query.Get("block"))
+		if err != nil {
+			respond.Error(w, r, fmt.Errorf("%w: invalid block rate: %q", errkit.ErrInvalidArgument, query.Get("block")))
+			return
+		}
+
+		mutexFraction, err := parseProfilerRate(query.Get("mutex"))
+		if err != nil {
+			respond.Error(w, r, fmt.Errorf("%w: invalid mutex fraction: %q", errkit.ErrInvalidArgument, query.Get("mutex")))
+			return
+		}
+
+		if blockRate >= 0 {
+			setBlockProfileRate(blockRate)
+		}
+
+		if mutexFraction >= 0 {
+			runtime.SetMutexProfileFraction(mutexFraction)
+		}
+
+	default:
+		respond.Status(w, r, http.StatusMethodNotAllowed)
+		return
+	}
+
+	respond.JSON(w, r, http.StatusOK, ProfilerRates{
+		BlockRate:     int(blockProfileRate.Load()),
+		MutexFraction: runtime.SetMutexProfileFraction(-1),
+	})
+}
+
+// parseProfilerRate parses the non-negative rate of the query parameter.
+// Returns -1 if the parameter is empty.
+func parseProfilerRate(v string) (int, error) {
+	if v == "" {
+		return -1, nil
+	}
+
+	rate, err := strconv.Atoi(v)
+	if err == nil && rate < 0 {
+		err = errors.New("negative rate")
+	}
+
+	return rate, err
+}
+
+// setBlockProfileRate sets the runtime block profile rate and remembers it.
+func setBlockProfileRate(rate int) {
+	runtime.SetBlockProfileRate(rate)
+	blockProfileRate.Store(int64(rate))
+}
@@ -0,0 +1,81 @@
+package servekit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestWithProfiler(t *testing.T) {
+	guard := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	l, err := New(":0", WithProfiler(
+		ProfilerRoute("/internal/pprof"),
+		ProfilerGuard(guard),
+		ProfilerBlockRate(0),
+		ProfilerMutexFraction(0),
+	))
+	td.Require(t).CmpNoError(err)
+
+	do := func(method, target string, authorized bool) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, nil)
+		if authorized {
+			r.Header.Set("Authorization", "secret")
+		}
+
+		w := httptest.NewRecorder()
+		l.server.Handler.ServeHTTP(w, r)
+
+		return w
+	}
+
+	td.Cmp(t, do(http.MethodGet, "/debug/pprof/", true).Code, http.StatusNotFound)
+	td.Cmp(t, do(http.MethodGet, "/internal/pprof/", false).Code, http.StatusUnauthorized)
+	td.Cmp(t, do(http.MethodGet, "/internal/pprof", true).Header().Get("Location"), "/internal/pprof/")
+	td.Cmp(t, do(http.MethodGet, "/internal/pprof/", true).Code, http.StatusOK)
+
+	for _, name := range profiles {
+		td.Cmp(t, do(http.MethodGet, "/internal/pprof/"+name, true).Code, http.StatusOK, name)
+	}
+
+	t.Run("Rates", func(t *testing.T) {
+		defer runtime.SetMutexProfileFraction(0)
+		defer setBlockProfileRate(0)
+
+		td.Cmp(t, do(http.MethodGet, "/internal/pprof/rates", true).Body.Bytes(),
+			td.Smuggle(json.RawMessage(nil), td.JSON(`{"blockRate": 0, "mutexFraction": 0}`)),
+		)
+
+		td.Cmp(t, do(http.MethodPost, "/internal/pprof/rates?block=1&mutex=5", true).Body.Bytes(),
+			td.Smuggle(json.RawMessage(nil), td.JSON(`{"blockRate": 1, "mutexFraction": 5}`)),
+		)
+
+		td.Cmp(t, do(http.MethodPost, "/internal/pprof/rates?block=-1", true).Code, http.StatusBadRequest)
+
+		// The valid block rate is not applied along with the invalid mutex fraction.
+		td.Cmp(t, do(http.MethodPost, "/internal/pprof/rates?block=2&mutex=x", true).Code, http.StatusBadRequest)
+		td.Cmp(t, do(http.MethodGet, "/internal/pprof/rates", true).Body.Bytes(),
+			td.Smuggle(json.RawMessage(nil), td.JSON(`{"blockRate": 1, "mutexFraction": 5}`)),
+		)
+
+		td.Cmp(t, do(http.MethodDelete, "/internal/pprof/rates", true).Code, http.StatusMethodNotAllowed)
+	})
+
+	t.Run("InvalidRoute", func(t *testing.T) {
+		_, err := New(":0", WithProfiler(ProfilerRoute("pprof")))
+		td.CmpContains(t, err, "invalid profiler route")
+	})
+}
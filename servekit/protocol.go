@@ -0,0 +1,94 @@
+package servekit
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// enableH2C wraps the server handler to serve HTTP/2 over cleartext TCP (h2c),
+// including both prior knowledge and HTTP/1.1 Upgrade modes.
+// The HTTP/2 connections are gracefully closed on the server shutdown.
+func enableH2C(server *http.Server) error {
+	h2s := &http2.Server{IdleTimeout: server.IdleTimeout}
+
+	// Registers the shutdown hook which gracefully
+	// closes HTTP/2 connections hijacked by h2c handler.
+	if err := http2.ConfigureServer(server, h2s); err != nil {
+		return err
+	}
+
+	server.Handler = h2c.NewHandler(server.Handler, h2s)
+
+	return nil
+}
+
+// listenHTTP3 binds the UDP socket and returns the HTTP/3 server which serves the same handler
+// as the main server. The certificate is taken from cert and key files, unless the server
+// TLS config already has one, e.g. set by TLS reload. Returned task serves the HTTP/3 server.
+func (l *ListenerHTTP) listenHTTP3(cert, key string) (*http3.Server, func(ctx context.Context) error, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS13}
+	if l.server.TLSConfig != nil {
+		tlsConfig = l.server.TLSConfig.Clone()
+	}
+
+	if tlsConfig.GetCertificate == nil && len(tlsConfig.Certificates) == 0 {
+		keyPair, err := tls.LoadX509KeyPair(cert, key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: http3: %w", ErrServeFailed, err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{keyPair}
+	}
+
+	addr := l.http3.addr
+	if addr == "" {
+		addr = l.server.Addr
+	}
+
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: http3: %w", ErrBindFailed, err)
+	}
+
+	server := &http3.Server{
+		Addr:           addr,
+		Handler:        l.server.Handler,
+		TLSConfig:      http3.ConfigureTLSConfig(tlsConfig),
+		MaxHeaderBytes: l.server.MaxHeaderBytes,
+	}
+
+	task := func(context.Context) error {
+		defer conn.Close()
+
+		l.logger.Info("ListenerHTTP started to listen HTTP/3 on: %s", conn.LocalAddr().String())
+
+		if err := server.Serve(conn); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("%w: http3: %w", ErrServeFailed, err)
+		}
+
+		return nil
+	}
+
+	return server, task, nil
+}
+
+// altSvcHandler wraps the next handler to announce the HTTP/3 support with Alt-Svc header.
+func altSvcHandler(server *http3.Server, next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		// SetQUICHeaders fails only when there is no port to announce,
+		// in that case the response is served without Alt-Svc header.
+		_ = server.SetQUICHeaders(w.Header())
+
+		next.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
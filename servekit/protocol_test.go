@@ -0,0 +1,100 @@
+package servekit
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/net/http2"
+)
+
+func TestListenerHTTP_H2C(t *testing.T) {
+	l, err := New("127.0.0.1:0", WithH2C())
+	td.Require(t).CmpNoError(err)
+
+	l.Mount("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte(r.Proto)) }))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- l.Serve(ctx) }()
+
+	td.Require(t).True(waitFor(func() bool { return l.Addr() != nil }))
+
+	client := http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}}
+
+	td.Cmp(t, readBody(t, &client, "http://"+l.Addr().String()), "HTTP/2.0")
+	td.Cmp(t, readBody(t, &testClient, "http://"+l.Addr().String()), "HTTP/1.1")
+
+	cancel()
+	td.CmpNoError(t, <-errCh)
+}
+
+func TestListenerHTTP_HTTP3(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+
+	writeTestKeyPair(t, certFile, keyFile, "server", time.Now().Add(time.Hour))
+
+	l, err := New("127.0.0.1:0", WithHTTP3())
+	td.Require(t).CmpNoError(err)
+
+	l.Mount("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte(r.Proto)) }))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- l.ServeTLS(ctx, certFile, keyFile) }()
+
+	td.Require(t).True(waitFor(func() bool { return l.Addr() != nil }))
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: true} //nolint:gosec
+
+	tcpClient := http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig, DisableKeepAlives: true}}
+
+	resp, err := tcpClient.Get("https://" + l.Addr().String()) //nolint:noctx
+	td.Require(t).CmpNoError(err)
+	td.CmpNoError(t, resp.Body.Close())
+
+	altSvc := regexp.MustCompile(`h3=":(\d+)"`).FindStringSubmatch(resp.Header.Get("Alt-Svc"))
+	td.Require(t).Len(altSvc, 2)
+
+	transport := &http3.RoundTripper{TLSClientConfig: tlsConfig}
+	defer transport.Close()
+
+	td.Cmp(t, readBody(t, &http.Client{Transport: transport}, "https://127.0.0.1:"+altSvc[1]), "HTTP/3.0")
+
+	cancel()
+	td.CmpNoError(t, <-errCh)
+}
+
+// readBody performs GET request to the url and returns the response body.
+func readBody(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+
+	resp, err := client.Get(url) //nolint:noctx
+	td.Require(t).CmpNoError(err)
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	td.Require(t).CmpNoError(err)
+
+	return string(body)
+}
@@ -17,39 +17,43 @@ var (
 
 	// errResponder represents the default implementation of ErrorResponder func.
 	errResponder ErrorResponder = func(w http.ResponseWriter, err error) {
-		if errors.Is(err, errkit.ErrAlreadyExists) {
-			http.Error(w, http.StatusText(http.StatusConflict), http.StatusConflict)
-			return
-		}
+		status := ErrorStatus(err)
+		http.Error(w, http.StatusText(status), status)
+	}
+)
 
-		if errors.Is(err, errkit.ErrNotFound) {
-			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
-			return
-		}
+// ErrorStatus maps err to the HTTP status code used by the default ErrorResponder.
+// Errors which do not wrap any known errkit error are mapped to HTTP 500 (Internal Server Error).
+func ErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errkit.ErrAlreadyExists):
+		return http.StatusConflict
 
-		if errors.Is(err, errkit.ErrUnauthenticated) {
-			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
-			return
-		}
+	case errors.Is(err, errkit.ErrNotFound):
+		return http.StatusNotFound
 
-		if errors.Is(err, errkit.ErrUnauthorized) {
-			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
-			return
-		}
+	case errors.Is(err, errkit.ErrUnauthenticated):
+		return http.StatusForbidden
 
-		if errors.Is(err, errkit.ErrInvalidArgument) {
-			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
-			return
-		}
+	case errors.Is(err, errkit.ErrUnauthorized):
+		return http.StatusUnauthorized
 
-		if errors.Is(err, errkit.ErrUnavailable) {
-			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
-			return
-		}
+	case errors.As(err, new(*http.MaxBytesError)):
+		return http.StatusRequestEntityTooLarge
 
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	case errors.Is(err, errkit.ErrInvalidArgument):
+		return http.StatusBadRequest
+
+	case errors.Is(err, errkit.ErrUnavailable):
+		return http.StatusServiceUnavailable
+
+	case errors.Is(err, errkit.ErrRateLimited):
+		return http.StatusTooManyRequests
+
+	default:
+		return http.StatusInternalServerError
 	}
-)
+}
 
 // WithErrorResponder sets the given responder as errResponder.
 func WithErrorResponder(responder ErrorResponder) {
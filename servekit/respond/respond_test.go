@@ -1 +1,36 @@
 package respond
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/heartwilltell/bones/errkit"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestErrorStatus(t *testing.T) {
+	type tcase struct {
+		err  error
+		want int
+	}
+
+	tests := map[string]tcase{
+		"AlreadyExists":   {err: errkit.ErrAlreadyExists, want: http.StatusConflict},
+		"NotFound":        {err: fmt.Errorf("user: %w", errkit.ErrNotFound), want: http.StatusNotFound},
+		"Unauthenticated": {err: errkit.ErrUnauthenticated, want: http.StatusForbidden},
+		"Unauthorized":    {err: errkit.ErrUnauthorized, want: http.StatusUnauthorized},
+		"InvalidArgument": {err: errkit.ErrInvalidArgument, want: http.StatusBadRequest},
+		"TooLarge":        {err: fmt.Errorf("%w: %w", errkit.ErrInvalidArgument, &http.MaxBytesError{Limit: 1}), want: http.StatusRequestEntityTooLarge},
+		"Unavailable":     {err: errkit.ErrUnavailable, want: http.StatusServiceUnavailable},
+		"RateLimited":     {err: errkit.ErrRateLimited, want: http.StatusTooManyRequests},
+		"Unknown":         {err: errors.New("boom"), want: http.StatusInternalServerError},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			td.Cmp(t, ErrorStatus(tc.err), tc.want)
+		})
+	}
+}
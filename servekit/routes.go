@@ -0,0 +1,155 @@
+package servekit
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/heartwilltell/bones/servekit/respond"
+)
+
+// packagePath matches the package paths in names of functions and types.
+var packagePath = regexp.MustCompile(`([\w.~-]+/)+`)
+
+// Route represents an endpoint served by the listener.
+type Route struct {
+	// Listener represents the name of the listener which serves
+	// the route: 'main' or 'admin' if the admin listener is enabled.
+	Listener string `json:"listener"`
+
+	// Host represents the virtual host pattern of the route mounted by MountHost.
+	// Empty for the routes served regardless of the Host header.
+	Host string `json:"host,omitempty"`
+
+	// Method represents the HTTP method of the route.
+	Method string `json:"method"`
+
+	// Pattern represents the chi route pattern.
+	Pattern string `json:"pattern"`
+
+	// Handler represents the name of the route handler.
+	Handler string `json:"handler"`
+
+	// Middlewares represents the names of middlewares applied
+	// to the route by the router, in order of their execution.
+	Middlewares []string `json:"middlewares"`
+}
+
+// Routes returns all routes served by the listener, including the routes mounted
+// by Mount and MountHost and builtin endpoints, sorted by listener, host, pattern and method.
+//
+// Routes registered for any method (e.g. by chi.Router.Handle) are listed for each method.
+// Note that middlewares applied by sub-routers mounted as plain http.Handler are not visible.
+func (l *ListenerHTTP) Routes() ([]Route, error) {
+	type listenerRouter struct {
+		name   string
+		host   string
+		router chi.Routes
+	}
+
+	routers := []listenerRouter{{name: mainListenerName, router: l.router}}
+
+	hosts, hostRouters := l.hosts.hosts()
+	for _, host := range hosts {
+		routers = append(routers, listenerRouter{name: mainListenerName, host: host, router: hostRouters[host]})
+	}
+
+	if l.adminRouter != nil {
+		routers = append(routers, listenerRouter{name: adminListenerName, router: l.adminRouter})
+	}
+
+	routes := make([]Route, 0)
+
+	for _, r := range routers {
+		walked, err := walkRoutes(r.router)
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk %s routes: %w", r.name, err)
+		}
+
+		for _, rt := range walked {
+			middlewares := make([]string, 0, len(rt.middlewares))
+			for _, mw := range rt.middlewares {
+				middlewares = append(middlewares, funcName(mw))
+			}
+
+			routes = append(routes, Route{
+				Listener:    r.name,
+				Host:        r.host,
+				Method:      rt.method,
+				Pattern:     rt.pattern,
+				Handler:     handlerName(rt.handler),
+				Middlewares: middlewares,
+			})
+		}
+	}
+
+	return routes, nil
+}
+
+// serveRoutes serves the list of routes returned by Routes as a table,
+// or as JSON if requested by the 'format' query parameter.
+func (l *ListenerHTTP) serveRoutes(w http.ResponseWriter, r *http.Request) {
+	routes, err := l.Routes()
+	if err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		respond.JSON(w, r, http.StatusOK, routes)
+		return
+	}
+
+	var b strings.Builder
+
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LISTENER\tHOST\tMETHOD\tPATTERN\tHANDLER\tMIDDLEWARES")
+
+	for _, rt := range routes {
+		host := rt.Host
+		if host == "" {
+			host = "*"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			rt.Listener, host, rt.Method, rt.Pattern, rt.Handler, strings.Join(rt.Middlewares, ", "),
+		)
+	}
+
+	if err := tw.Flush(); err != nil {
+		respond.Error(w, r, err)
+		return
+	}
+
+	respond.TEXT(w, r, http.StatusOK, []byte(b.String()))
+}
+
+// handlerName returns the name of the handler function, or its type name if the handler is not a function.
+func handlerName(h http.Handler) string {
+	if fn, ok := h.(http.HandlerFunc); ok {
+		return funcName(fn)
+	}
+
+	return packagePath.ReplaceAllString(strings.TrimPrefix(reflect.TypeOf(h).String(), "*"), "")
+}
+
+// funcName returns the name of the function fn without the package paths,
+// e.g. 'middleware.LoggingMiddleware.func1'.
+func funcName(fn any) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return reflect.TypeOf(fn).String()
+	}
+
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return v.Type().String()
+	}
+
+	return packagePath.ReplaceAllString(f.Name(), "")
+}
@@ -0,0 +1,89 @@
+package servekit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/heartwilltell/bones/servekit/middleware"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func testMiddleware(next http.Handler) http.Handler { return next }
+
+func TestListenerHTTP_Routes(t *testing.T) {
+	l, err := New(":0",
+		WithAdminListener("127.0.0.1:0"),
+		WithGlobalMiddlewares(middleware.MetricsMiddleware()),
+		WithHealthCheck(),
+		WithRoutes(RoutesGuard(testMiddleware)),
+	)
+	td.Require(t).CmpNoError(err)
+
+	api := chi.NewRouter()
+	api.With(testMiddleware).Get("/items/{id}", func(http.ResponseWriter, *http.Request) {})
+
+	Handle(api, http.MethodDelete, "/items/{id}", func(context.Context, struct{}) (testCreated, error) {
+		return testCreated{}, nil
+	})
+
+	l.Mount("/api", api, testMiddleware)
+
+	routes, err := l.Routes()
+	td.Require(t).CmpNoError(err)
+
+	td.Cmp(t, routes, []Route{
+		{
+			Listener:    mainListenerName,
+			Method:      http.MethodDelete,
+			Pattern:     "/api/items/{id}",
+			Handler:     "servekit.typedHandler[struct {},servekit.testCreated]",
+			Middlewares: []string{"middleware.MetricsMiddleware.func1", "servekit.testMiddleware"},
+		},
+		{
+			Listener:    mainListenerName,
+			Method:      http.MethodGet,
+			Pattern:     "/api/items/{id}",
+			Handler:     "servekit.TestListenerHTTP_Routes.func1",
+			Middlewares: []string{"middleware.MetricsMiddleware.func1", "servekit.testMiddleware", "servekit.testMiddleware"},
+		},
+		{
+			Listener:    adminListenerName,
+			Method:      http.MethodGet,
+			Pattern:     "/debug/routes",
+			Handler:     "servekit.(*ListenerHTTP).serveRoutes-fm",
+			Middlewares: []string{"servekit.testMiddleware"},
+		},
+		{Listener: adminListenerName, Method: http.MethodGet, Pattern: "/health", Handler: "servekit.(*ListenerHTTP).healthCheck-fm", Middlewares: []string{}},
+		{Listener: adminListenerName, Method: http.MethodGet, Pattern: "/livez", Handler: "servekit.(*ListenerHTTP).liveness-fm", Middlewares: []string{}},
+		{Listener: adminListenerName, Method: http.MethodGet, Pattern: "/readyz", Handler: "servekit.(*ListenerHTTP).readinessCheck-fm", Middlewares: []string{}},
+	})
+
+	t.Run("Endpoint", func(t *testing.T) {
+		do := func(target string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			l.adminRouter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+			return w
+		}
+
+		resp := do("/debug/routes")
+		td.Cmp(t, resp.Code, http.StatusOK)
+		td.Cmp(t, resp.Header().Get("Content-Type"), "text/plain; charset=utf-8")
+		td.CmpContains(t, resp.Body.String(), "LISTENER  HOST  METHOD  PATTERN")
+		td.CmpContains(t, resp.Body.String(), "main      *     GET     /api/items/{id}  servekit.TestListenerHTTP_Routes.func1")
+
+		resp = do("/debug/routes?format=json")
+		td.Cmp(t, resp.Code, http.StatusOK)
+		td.Cmp(t, resp.Body.Bytes(), td.Smuggle(json.RawMessage(nil), td.JSONPointer("/2", td.JSON(`{
+			"listener": "admin",
+			"method": "GET",
+			"pattern": "/debug/routes",
+			"handler": "servekit.(*ListenerHTTP).serveRoutes-fm",
+			"middlewares": ["servekit.testMiddleware"]
+		}`))))
+	})
+}
@@ -0,0 +1,439 @@
+package servekit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/heartwilltell/log"
+	"go.uber.org/multierr"
+)
+
+const (
+	// componentStartTimeout represents default timeout of the component start.
+	componentStartTimeout = 30 * time.Second
+
+	// componentStopTimeout represents default timeout of the component stop.
+	componentStopTimeout = 30 * time.Second
+)
+
+// Component represents a part of the application managed by Run,
+// e.g. ListenerHTTP or connection to the database.
+type Component interface {
+	// Start starts the component. Components which keep running in the background,
+	// e.g. listeners, should return as soon as they are ready and implement
+	// BackgroundComponent to tell when they stop running on their own.
+	Start(ctx context.Context) error
+
+	// Stop stops the component and releases its resources.
+	Stop(ctx context.Context) error
+}
+
+// BackgroundComponent represents the Component which keeps running in the background after
+// Start. Run stops the application when any of the background components stops running.
+type BackgroundComponent interface {
+	Component
+
+	// Wait returns the channel which receives the error, or nil,
+	// when the component stops running before Stop is called.
+	Wait() <-chan error
+}
+
+// RunnerConfig represents configuration of the Runner.
+type RunnerConfig struct {
+	logger       log.Logger
+	signals      []os.Signal
+	startTimeout time.Duration
+	stopTimeout  time.Duration
+}
+
+// RunnerLogger represents an optional function for NewRunner function.
+// If passed to the NewRunner, will set the config.logger.
+func RunnerLogger(l log.Logger) Option[*RunnerConfig] {
+	return func(c *RunnerConfig) {
+		if l != nil {
+			c.logger = l
+		}
+	}
+}
+
+// RunnerSignals represents an optional function for NewRunner function.
+// If passed to the NewRunner, will set the config.signals which stop
+// the application. Default are SIGINT and SIGTERM.
+func RunnerSignals(signals ...os.Signal) Option[*RunnerConfig] {
+	return func(c *RunnerConfig) { c.signals = signals }
+}
+
+// RunnerStartTimeout represents an optional function for NewRunner function.
+// If passed to the NewRunner, will set the config.startTimeout, which is the default timeout
+// of each component start. Use ComponentStartTimeout to set the timeout of the component.
+func RunnerStartTimeout(timeout time.Duration) Option[*RunnerConfig] {
+	return func(c *RunnerConfig) { c.startTimeout = timeout }
+}
+
+// RunnerStopTimeout represents an optional function for NewRunner function.
+// If passed to the NewRunner, will set the config.stopTimeout, which is the default timeout
+// of each component stop. Use ComponentStopTimeout to set the timeout of the component.
+func RunnerStopTimeout(timeout time.Duration) Option[*RunnerConfig] {
+	return func(c *RunnerConfig) { c.stopTimeout = timeout }
+}
+
+// Runner runs the components of the application.
+type Runner struct {
+	cfg RunnerConfig
+}
+
+// NewRunner returns a new instance of Runner.
+// Receives the following options to configure the runner:
+// - RunnerLogger - to log the components start and stop.
+// - RunnerSignals - to set the signals which stop the application.
+// - RunnerStartTimeout - to set the default timeout of the components start.
+// - RunnerStopTimeout - to set the default timeout of the components stop.
+func NewRunner(options ...Option[*RunnerConfig]) *Runner {
+	cfg := RunnerConfig{
+		logger:       log.NewNopLog(),
+		signals:      []os.Signal{syscall.SIGINT, syscall.SIGTERM},
+		startTimeout: componentStartTimeout,
+		stopTimeout:  componentStopTimeout,
+	}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	return &Runner{cfg: cfg}
+}
+
+// Run runs the components by the Runner with default settings.
+// See Runner.Run for details.
+func Run(ctx context.Context, components ...Component) error {
+	return NewRunner().Run(ctx, components...)
+}
+
+// Run starts the components in the given order and blocks until the ctx is canceled, one of
+// the signals is received, or any of the background components stops running. Then the started
+// components are stopped in reverse order, even if some of them fail to stop.
+//
+// If any component fails to start, the components started before it are stopped and the start
+// error is returned. The returned error aggregates the error of the background component which
+// stopped the application and the errors of the components stop.
+//
+// Example:
+//
+//	err := servekit.Run(ctx,
+//		servekit.Configure(pg, servekit.ComponentName("postgres")),
+//		servekit.Configure(nats, servekit.ComponentName("nats"), servekit.ComponentStopTimeout(10*time.Second)),
+//		listener,
+//		servekit.Func("sentry", nil, func(context.Context) error { return sentrykit.Close() }),
+//	)
+func (r *Runner) Run(ctx context.Context, components ...Component) error {
+	ctx, stop := signal.NotifyContext(ctx, r.cfg.signals...)
+	defer stop()
+
+	started := make([]Component, 0, len(components))
+
+	// failed receives the result of the first background component which stops running.
+	failed := make(chan componentResult, len(components))
+
+	// done stops waiting for the background components, since the stopped
+	// ones are not required to send anything to their Wait channels.
+	done := make(chan struct{})
+
+	var runErr error
+
+	for _, c := range components {
+		if err := r.start(ctx, c); err != nil {
+			runErr = err
+			break
+		}
+
+		started = append(started, c)
+
+		if bc, ok := c.(BackgroundComponent); ok {
+			go func(c Component, wait <-chan error) {
+				select {
+				case err := <-wait:
+					failed <- componentResult{component: c, err: err}
+				case <-done:
+				}
+			}(c, bc.Wait())
+		}
+	}
+
+	if runErr == nil {
+		select {
+		case <-ctx.Done():
+			r.cfg.logger.Info("Stopping the application")
+
+		case res := <-failed:
+			r.cfg.logger.Info("Stopping the application: %s stopped running", componentName(res.component))
+
+			if res.err != nil {
+				runErr = fmt.Errorf("%s: %w", componentName(res.component), res.err)
+			}
+		}
+	}
+
+	close(done)
+
+	for i := len(started) - 1; i >= 0; i-- {
+		multierr.AppendInto(&runErr, r.stop(started[i]))
+	}
+
+	return runErr
+}
+
+// start starts the component with the start timeout.
+func (r *Runner) start(ctx context.Context, c Component) error {
+	name := componentName(c)
+
+	timeout := r.cfg.startTimeout
+	if cc, ok := c.(interface{ config() ComponentConfig }); ok && cc.config().startTimeout > 0 {
+		timeout = cc.config().startTimeout
+	}
+
+	startCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	r.cfg.logger.Info("Starting %s", name)
+	start := time.Now()
+
+	if err := c.Start(startCtx); err != nil {
+		r.cfg.logger.Error("Failed to start %s: %s", name, err.Error())
+		return fmt.Errorf("failed to start %s: %w", name, err)
+	}
+
+	r.cfg.logger.Info("Started %s in %s", name, time.Since(start).String())
+
+	return nil
+}
+
+// stop stops the component with the stop timeout. The stop is not waited
+// for longer than the timeout, even if the component ignores its ctx.
+func (r *Runner) stop(c Component) error {
+	name := componentName(c)
+
+	timeout := r.cfg.stopTimeout
+	if cc, ok := c.(interface{ config() ComponentConfig }); ok && cc.config().stopTimeout > 0 {
+		timeout = cc.config().stopTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	r.cfg.logger.Info("Stopping %s", name)
+	start := time.Now()
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- c.Stop(ctx) }()
+
+	var err error
+
+	select {
+	case err = <-stopped:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+
+	if err != nil {
+		r.cfg.logger.Error("Failed to stop %s: %s", name, err.Error())
+		return fmt.Errorf("failed to stop %s: %w", name, err)
+	}
+
+	r.cfg.logger.Info("Stopped %s in %s", name, time.Since(start).String())
+
+	return nil
+}
+
+// componentResult represents the result of the background component.
+type componentResult struct {
+	component Component
+	err       error
+}
+
+// componentName returns the name of the component used in logs and errors. The name is
+// returned by the Name method of the component if any, otherwise the type name is used.
+func componentName(c Component) string {
+	if named, ok := c.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+
+	return fmt.Sprintf("%T", c)
+}
+
+// ComponentConfig represents configuration of the component managed by Run.
+type ComponentConfig struct {
+	name         string
+	startTimeout time.Duration
+	stopTimeout  time.Duration
+}
+
+// ComponentName represents an optional function for Configure function.
+// If passed to the Configure, will set the config.name used in logs and errors.
+func ComponentName(name string) Option[*ComponentConfig] {
+	return func(c *ComponentConfig) { c.name = name }
+}
+
+// ComponentStartTimeout represents an optional function for Configure function.
+// If passed to the Configure, will set the config.startTimeout.
+func ComponentStartTimeout(timeout time.Duration) Option[*ComponentConfig] {
+	return func(c *ComponentConfig) { c.startTimeout = timeout }
+}
+
+// ComponentStopTimeout represents an optional function for Configure function.
+// If passed to the Configure, will set the config.stopTimeout.
+func ComponentStopTimeout(timeout time.Duration) Option[*ComponentConfig] {
+	return func(c *ComponentConfig) { c.stopTimeout = timeout }
+}
+
+// Configure returns the component which overrides the settings of the given one.
+// Receives the following options to configure the component:
+// - ComponentName - to set the name of the component.
+// - ComponentStartTimeout - to set the timeout of the component start.
+// - ComponentStopTimeout - to set the timeout of the component stop.
+func Configure(c Component, options ...Option[*ComponentConfig]) Component {
+	cfg := ComponentConfig{name: componentName(c)}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	cc := configuredComponent{Component: c, cfg: cfg}
+
+	if bc, ok := c.(BackgroundComponent); ok {
+		return &configuredBackgroundComponent{configuredComponent: cc, wait: bc.Wait}
+	}
+
+	return &cc
+}
+
+type configuredComponent struct {
+	Component
+	cfg ComponentConfig
+}
+
+func (c *configuredComponent) Name() string { return c.cfg.name }
+
+func (c *configuredComponent) config() ComponentConfig { return c.cfg }
+
+type configuredBackgroundComponent struct {
+	configuredComponent
+	wait func() <-chan error
+}
+
+func (c *configuredBackgroundComponent) Wait() <-chan error { return c.wait() }
+
+// Func returns the component with the given name, which calls start on start
+// and stop on stop. Nil functions are skipped.
+func Func(name string, start, stop func(ctx context.Context) error) Component {
+	return &funcComponent{name: name, start: start, stop: stop}
+}
+
+type funcComponent struct {
+	name  string
+	start func(ctx context.Context) error
+	stop  func(ctx context.Context) error
+}
+
+func (c *funcComponent) Name() string { return c.name }
+
+func (c *funcComponent) Start(ctx context.Context) error {
+	if c.start == nil {
+		return nil
+	}
+
+	return c.start(ctx)
+}
+
+func (c *funcComponent) Stop(ctx context.Context) error {
+	if c.stop == nil {
+		return nil
+	}
+
+	return c.stop(ctx)
+}
+
+// Closer returns the component with the given name, which closes c on stop.
+func Closer(name string, c io.Closer) Component {
+	return Func(name, nil, func(context.Context) error { return c.Close() })
+}
+
+// Background returns the background component with the given name, which runs fn
+// in the background until its ctx is canceled on stop, e.g. to run the listener
+// by ServeTLS or the message consumer:
+//
+//	servekit.Background("https", func(ctx context.Context) error {
+//		return listener.ServeTLS(ctx, cert, key)
+//	})
+func Background(name string, fn func(ctx context.Context) error) BackgroundComponent {
+	return &backgroundComponent{name: name, fn: fn}
+}
+
+type backgroundComponent struct {
+	name string
+	fn   func(ctx context.Context) error
+
+	cancel context.CancelFunc
+	wait   chan error
+	done   chan struct{}
+
+	// mu guards stopping and err, to deliver the
+	// error either by Wait or by Stop, but not both.
+	mu       sync.Mutex
+	stopping bool
+	err      error
+}
+
+func (c *backgroundComponent) Name() string { return c.name }
+
+func (c *backgroundComponent) Start(ctx context.Context) error {
+	// The start ctx is canceled as soon as Start returns.
+	ctx, c.cancel = context.WithCancel(context.WithoutCancel(ctx))
+
+	c.wait = make(chan error, 1)
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+
+		err := c.fn(ctx)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if c.stopping {
+			c.err = err
+			return
+		}
+
+		c.wait <- err
+	}()
+
+	return nil
+}
+
+func (c *backgroundComponent) Wait() <-chan error { return c.wait }
+
+func (c *backgroundComponent) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	c.stopping = true
+	c.mu.Unlock()
+
+	c.cancel()
+
+	select {
+	case <-c.done:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		return c.err
+
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
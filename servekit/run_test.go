@@ -0,0 +1,230 @@
+package servekit
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// recorder records the calls of the components in order.
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) record(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, call)
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.calls...)
+}
+
+// component returns the component which records its calls and fails with the given errors.
+func (r *recorder) component(name string, startErr, stopErr error) Component {
+	return Func(name,
+		func(context.Context) error { r.record("start " + name); return startErr },
+		func(context.Context) error { r.record("stop " + name); return stopErr },
+	)
+}
+
+func TestRun(t *testing.T) {
+	t.Run("Canceled", func(t *testing.T) {
+		var rec recorder
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		errCh := make(chan error, 1)
+		go func() { errCh <- Run(ctx, rec.component("a", nil, nil), rec.component("b", nil, nil)) }()
+
+		td.Require(t).True(waitFor(func() bool { return len(rec.get()) == 2 }))
+		cancel()
+
+		td.CmpNoError(t, <-errCh)
+		td.Cmp(t, rec.get(), []string{"start a", "start b", "stop b", "stop a"})
+	})
+
+	t.Run("Signal", func(t *testing.T) {
+		var rec recorder
+
+		started := make(chan struct{})
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- NewRunner(RunnerSignals(syscall.SIGUSR1)).Run(context.Background(),
+				rec.component("a", nil, nil),
+				Func("started", func(context.Context) error { close(started); return nil }, nil),
+			)
+		}()
+
+		<-started
+		td.Require(t).CmpNoError(syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+
+		td.CmpNoError(t, <-errCh)
+		td.Cmp(t, rec.get(), []string{"start a", "stop a"})
+	})
+
+	t.Run("StartFailed", func(t *testing.T) {
+		var rec recorder
+
+		err := Run(context.Background(),
+			rec.component("a", nil, nil),
+			rec.component("b", nil, errors.New("stop failed")),
+			rec.component("c", errors.New("start failed"), nil),
+			rec.component("d", nil, nil),
+		)
+
+		td.Cmp(t, err, td.String("failed to start c: start failed; failed to stop b: stop failed"))
+		td.Cmp(t, rec.get(), []string{"start a", "start b", "start c", "stop b", "stop a"})
+	})
+
+	t.Run("BackgroundFailed", func(t *testing.T) {
+		var rec recorder
+
+		worker := Background("worker", func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				return nil
+
+			case <-time.After(50 * time.Millisecond):
+				return errors.New("boom")
+			}
+		})
+
+		err := Run(context.Background(), rec.component("a", nil, nil), worker, rec.component("b", nil, nil))
+
+		td.Cmp(t, err, td.String("worker: boom"))
+		td.Cmp(t, rec.get(), []string{"start a", "start b", "stop b", "stop a"})
+	})
+
+	t.Run("BackgroundStopped", func(t *testing.T) {
+		stopErr := errors.New("stopped with error")
+
+		worker := Background("worker", func(ctx context.Context) error {
+			<-ctx.Done()
+			return stopErr
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		td.Cmp(t, Run(ctx, worker), td.ErrorIs(stopErr))
+	})
+
+	t.Run("BackgroundNotWaited", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// The first run starts the signal handling goroutine, which never exits.
+		td.Require(t).CmpNoError(Run(ctx))
+
+		goroutines := runtime.NumGoroutine()
+
+		// The workers stopped by Stop send nothing to their Wait channels.
+		workers := make([]Component, 0, 10)
+		for i := 0; i < cap(workers); i++ {
+			workers = append(workers, Background("worker", func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			}))
+		}
+
+		td.CmpNoError(t, Run(ctx, workers...))
+		td.CmpTrue(t, waitFor(func() bool { return runtime.NumGoroutine() <= goroutines }))
+	})
+
+	t.Run("StopTimeout", func(t *testing.T) {
+		var rec recorder
+
+		slow := Configure(Func("slow", nil, func(context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}), ComponentStopTimeout(50*time.Millisecond))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		start := time.Now()
+
+		err := NewRunner(RunnerStopTimeout(time.Minute)).Run(ctx, rec.component("a", nil, nil), slow)
+		td.Cmp(t, err, td.String("failed to stop slow: timed out after 50ms"))
+		td.Cmp(t, time.Since(start), td.Lt(time.Second))
+		td.Cmp(t, rec.get(), []string{"start a", "stop a"})
+	})
+
+	t.Run("StartTimeout", func(t *testing.T) {
+		slow := Func("slow", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, nil)
+
+		err := NewRunner(RunnerStartTimeout(50*time.Millisecond)).Run(context.Background(), slow)
+		td.Cmp(t, err, td.ErrorIs(context.DeadlineExceeded))
+	})
+}
+
+func TestListenerHTTP_Start(t *testing.T) {
+	l, err := New("127.0.0.1:0")
+	td.Require(t).CmpNoError(err)
+
+	l.Mount("/", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// The listener is bound when the next component starts.
+	client := Func("client", func(context.Context) error {
+		resp, err := testClient.Get("http://" + l.Addr().String()) //nolint:noctx
+		if err != nil {
+			return err
+		}
+
+		resp.Body.Close()
+		cancel()
+
+		return nil
+	}, nil)
+
+	td.CmpNoError(t, Run(ctx, l, client))
+	td.Cmp(t, l.Addr(), td.NotNil())
+
+	t.Run("BindFailed", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		td.Require(t).CmpNoError(err)
+
+		defer ln.Close()
+
+		l, err := New(ln.Addr().String())
+		td.Require(t).CmpNoError(err)
+
+		td.Cmp(t, Run(context.Background(), l), td.ErrorIs(ErrBindFailed))
+	})
+
+	t.Run("AdminBindFailed", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		td.Require(t).CmpNoError(err)
+
+		defer ln.Close()
+
+		l, err := New("127.0.0.1:0", WithAdminListener(ln.Addr().String()))
+		td.Require(t).CmpNoError(err)
+
+		td.Cmp(t, Run(context.Background(), l), td.ErrorIs(ErrBindFailed))
+	})
+}
@@ -0,0 +1,149 @@
+package servekit
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+)
+
+const (
+	// mainListenerName represents the metrics label value of the main listener.
+	mainListenerName = "main"
+
+	// adminListenerName represents the metrics label value of the admin listener.
+	adminListenerName = "admin"
+)
+
+// serverStats collects server-level metrics of the http.Server:
+// open connections by state, accepted connections, in-flight requests
+// and shutdown duration.
+type serverStats struct {
+	listener string
+
+	// states holds the last known state of each open connection.
+	states sync.Map
+
+	accepted *metrics.Counter
+	inFlight *atomic.Int64
+	shutdown *atomic.Uint64
+}
+
+// newServerStats returns a pointer to a new instance of serverStats
+// which labels the metrics by the given listener name.
+func newServerStats(listener string) *serverStats {
+	return &serverStats{
+		listener: listener,
+		accepted: metrics.GetOrCreateCounter(fmt.Sprintf(`http_server_connections_accepted_total{listener=%q}`, listener)),
+		inFlight: intGauge(fmt.Sprintf(`http_server_requests_in_flight{listener=%q}`, listener)),
+		shutdown: floatGauge(fmt.Sprintf(`http_server_shutdown_duration_seconds{listener=%q}`, listener)),
+	}
+}
+
+// instrument sets the http.Server ConnState hook and wraps
+// its handler to count in-flight requests.
+func (s *serverStats) instrument(server *http.Server) {
+	server.ConnState = s.connState
+	server.Handler = s.handler(server.Handler)
+}
+
+// connState implements the http.Server ConnState hook.
+func (s *serverStats) connState(conn net.Conn, state http.ConnState) {
+	if state == http.StateNew {
+		s.accepted.Inc()
+	}
+
+	if prev, ok := s.states.Load(conn); ok {
+		s.connections(prev.(http.ConnState)).Add(-1)
+	}
+
+	switch state {
+	case http.StateHijacked, http.StateClosed:
+		s.states.Delete(conn)
+
+	case http.StateNew, http.StateActive, http.StateIdle:
+		s.states.Store(conn, state)
+		s.connections(state).Add(1)
+	}
+}
+
+// count returns the number of open connections in the given state.
+func (s *serverStats) count(state http.ConnState) int {
+	var n int
+
+	s.states.Range(func(_, v any) bool {
+		if v.(http.ConnState) == state {
+			n++
+		}
+
+		return true
+	})
+
+	return n
+}
+
+// connections returns the gauge value of open connections in the given state.
+func (s *serverStats) connections(state http.ConnState) *atomic.Int64 {
+	return intGauge(
+		fmt.Sprintf(`http_server_connections{listener=%q, state=%q}`, s.listener, state.String()),
+	)
+}
+
+// handler wraps the next handler to count in-flight requests.
+func (s *serverStats) handler(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		s.inFlight.Add(1)
+		defer s.inFlight.Add(-1)
+
+		next.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// observeShutdown records the duration of the shutdown started at start.
+func (s *serverStats) observeShutdown(start time.Time) {
+	s.shutdown.Store(math.Float64bits(time.Since(start).Seconds()))
+}
+
+// gauges holds the values of the gauges by the metric name. The values are shared by the
+// listeners with the same name, since the gauge callback is registered only once per name.
+var gauges sync.Map
+
+// intGauge returns the value of the gauge with the given name, registering the gauge on the first call.
+func intGauge(name string) *atomic.Int64 {
+	if v, ok := gauges.Load(name); ok {
+		return v.(*atomic.Int64)
+	}
+
+	v, loaded := gauges.LoadOrStore(name, new(atomic.Int64))
+	value := v.(*atomic.Int64)
+
+	if !loaded {
+		metrics.GetOrCreateGauge(name, func() float64 { return float64(value.Load()) })
+	}
+
+	return value
+}
+
+// floatGauge returns the bits of the float value of the gauge with the given name,
+// registering the gauge on the first call.
+func floatGauge(name string) *atomic.Uint64 {
+	if v, ok := gauges.Load(name); ok {
+		return v.(*atomic.Uint64)
+	}
+
+	v, loaded := gauges.LoadOrStore(name, new(atomic.Uint64))
+	value := v.(*atomic.Uint64)
+
+	if !loaded {
+		metrics.GetOrCreateGauge(name, func() float64 { return math.Float64frombits(value.Load()) })
+	}
+
+	return value
+}
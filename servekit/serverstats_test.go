@@ -0,0 +1,67 @@
+package servekit
+
+import (
+	"bytes"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestServerStats(t *testing.T) {
+	name := "test-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	stats := newServerStats(name)
+
+	var inFlight int64
+
+	server := &http.Server{
+		ReadHeaderTimeout: time.Second,
+		Handler: http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			inFlight = stats.inFlight.Load()
+		}),
+	}
+	stats.instrument(server)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	td.Require(t).CmpNoError(err)
+
+	go func() { _ = server.Serve(ln) }()
+
+	defer server.Close()
+
+	transport := &http.Transport{}
+	client := http.Client{Transport: transport}
+
+	resp, err := client.Get("http://" + ln.Addr().String()) //nolint:noctx
+	td.Require(t).CmpNoError(err)
+	td.CmpNoError(t, resp.Body.Close())
+
+	td.Cmp(t, inFlight, int64(1))
+	td.Cmp(t, stats.inFlight.Load(), int64(0))
+	td.Cmp(t, stats.accepted.Get(), uint64(1))
+	td.CmpTrue(t, waitFor(func() bool { return stats.connections(http.StateIdle).Load() == 1 }))
+	td.Cmp(t, stats.connections(http.StateActive).Load(), int64(0))
+
+	transport.CloseIdleConnections()
+
+	td.CmpTrue(t, waitFor(func() bool { return stats.connections(http.StateIdle).Load() == 0 }))
+
+	stats.observeShutdown(time.Now().Add(-time.Second))
+	td.Cmp(t, math.Float64frombits(stats.shutdown.Load()), td.Gte(1.0))
+
+	// The values which go down are exposed as gauges.
+	var buf bytes.Buffer
+	metrics.WritePrometheus(&buf, false)
+
+	td.CmpContains(t, buf.String(), `http_server_requests_in_flight{listener="`+name+`"} 0`)
+	td.CmpContains(t, buf.String(), `http_server_connections{listener="`+name+`", state="idle"} 0`)
+	td.Cmp(t, buf.String(), td.Re(`http_server_shutdown_duration_seconds\{listener="`+name+`"\} 1\.`))
+
+	// The listeners with the same name share the values.
+	td.Cmp(t, newServerStats(name).shutdown, td.Shallow(stats.shutdown))
+}
@@ -0,0 +1,73 @@
+package servetest
+
+import (
+	"context"
+	"net"
+	"sync"
+)
+
+// memoryAddr represents the address of the in-memory listener.
+type memoryAddr struct{}
+
+func (memoryAddr) Network() string { return "memory" }
+func (memoryAddr) String() string  { return "memory" }
+
+// memoryListener implements net.Listener which accepts
+// the in-memory connections created by net.Pipe.
+type memoryListener struct {
+	conns     chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newMemoryListener() *memoryListener {
+	return &memoryListener{
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+}
+
+func (l *memoryListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *memoryListener) Close() error {
+	l.closeOnce.Do(func() { close(l.done) })
+	return nil
+}
+
+func (l *memoryListener) Addr() net.Addr { return memoryAddr{} }
+
+// DialContext connects to the listener if addr is the listener address,
+// otherwise it dials the network. Its signature matches http.Transport.DialContext.
+func (l *memoryListener) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if host, _, _ := net.SplitHostPort(addr); host != l.Addr().String() {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, network, addr)
+	}
+
+	server, client := net.Pipe()
+
+	select {
+	case l.conns <- server:
+		return client, nil
+
+	case <-l.done:
+		server.Close()
+		client.Close()
+
+		return nil, net.ErrClosed
+
+	case <-ctx.Done():
+		server.Close()
+		client.Close()
+
+		return nil, ctx.Err()
+	}
+}
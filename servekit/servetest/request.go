@@ -0,0 +1,188 @@
+package servetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// Request represents the request to the listener under test.
+// Any error occurred while building the request fails the test on Do.
+type Request struct {
+	t      testing.TB
+	client *http.Client
+	req    *http.Request
+	err    error
+}
+
+func newRequest(t testing.TB, client *http.Client, method, target string) *Request {
+	req, err := http.NewRequest(method, target, http.NoBody) //nolint:noctx // Test requests are bound by the listener lifetime.
+
+	return &Request{t: t, client: client, req: req, err: err}
+}
+
+// Header sets the request header.
+func (r *Request) Header(key, value string) *Request {
+	if r.err == nil {
+		r.req.Header.Set(key, value)
+	}
+
+	return r
+}
+
+// Query adds the URL query parameter to the request.
+func (r *Request) Query(key, value string) *Request {
+	if r.err == nil {
+		query := r.req.URL.Query()
+		query.Add(key, value)
+		r.req.URL.RawQuery = query.Encode()
+	}
+
+	return r
+}
+
+// Body sets the request body with the given content type.
+func (r *Request) Body(contentType string, body []byte) *Request {
+	if r.err == nil {
+		r.req.Header.Set("Content-Type", contentType)
+		r.req.Body = io.NopCloser(bytes.NewReader(body))
+		r.req.ContentLength = int64(len(body))
+		r.req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	}
+
+	return r
+}
+
+// JSON sets the request body to JSON representation of v.
+func (r *Request) JSON(v any) *Request {
+	body, err := json.Marshal(v)
+	if err != nil {
+		r.err = fmt.Errorf("failed to encode request body: %w", err)
+		return r
+	}
+
+	return r.Body("application/json", body)
+}
+
+// Form sets the request body to URL-encoded form values.
+func (r *Request) Form(values url.Values) *Request {
+	return r.Body("application/x-www-form-urlencoded", []byte(values.Encode()))
+}
+
+// Do sends the request and reads the response. Fails the test if the request cannot be sent.
+func (r *Request) Do() *Response {
+	r.t.Helper()
+
+	if r.err != nil {
+		r.t.Fatalf("servetest: invalid request: %s", r.err)
+	}
+
+	resp, err := r.client.Do(r.req)
+	if err != nil {
+		r.t.Fatalf("servetest: %s %s: %s", r.req.Method, r.req.URL.Path, err)
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		r.t.Fatalf("servetest: %s %s: failed to read response body: %s", r.req.Method, r.req.URL.Path, err)
+	}
+
+	return &Response{
+		Response: resp,
+		t:        r.t,
+		name:     r.req.Method + " " + r.req.URL.Path,
+		body:     body,
+	}
+}
+
+// Response represents the response of the listener under test.
+// Its assertion methods report failures by go-testdeep and can be chained.
+// The expected values are either plain values or go-testdeep operators.
+type Response struct {
+	*http.Response
+
+	t    testing.TB
+	name string
+	body []byte
+}
+
+// Status checks the response status code.
+func (r *Response) Status(expected any) *Response {
+	r.t.Helper()
+
+	td.Cmp(r.t, r.StatusCode, expected, "%s: status code", r.name)
+
+	return r
+}
+
+// Header checks the value of the response header. Use td.Empty to check the header is absent.
+func (r *Response) Header(key string, expected any) *Response {
+	r.t.Helper()
+
+	td.Cmp(r.t, r.Response.Header.Get(key), expected, "%s: header %s", r.name, key)
+
+	return r
+}
+
+// Body checks the response body as a string.
+func (r *Response) Body(expected any) *Response {
+	r.t.Helper()
+
+	td.Cmp(r.t, string(r.body), expected, "%s: body", r.name)
+
+	return r
+}
+
+// JSON checks the JSON response body. If expected is a go-testdeep operator, e.g. td.JSON or
+// td.SuperJSONOf, the raw body is compared to it. Otherwise, the body is decoded into the value
+// of the same type as expected, and compared to it.
+func (r *Response) JSON(expected any) *Response {
+	r.t.Helper()
+
+	if !strings.Contains(r.Response.Header.Get("Content-Type"), "json") {
+		r.t.Errorf("%s: unexpected content type %q", r.name, r.Response.Header.Get("Content-Type"))
+	}
+
+	if expected == nil {
+		expected = td.JSON("null")
+	}
+
+	if operator, ok := expected.(td.TestDeep); ok {
+		td.Cmp(r.t, json.RawMessage(r.body), operator, "%s: JSON body", r.name)
+		return r
+	}
+
+	got := reflect.New(reflect.TypeOf(expected))
+	if err := json.Unmarshal(r.body, got.Interface()); err != nil {
+		r.t.Errorf("%s: failed to decode JSON body: %s", r.name, err)
+		return r
+	}
+
+	td.Cmp(r.t, got.Elem().Interface(), expected, "%s: JSON body", r.name)
+
+	return r
+}
+
+// Bytes returns the response body.
+func (r *Response) Bytes() []byte { return r.body }
+
+// Decode decodes the JSON response body into v. Fails the test if the body cannot be decoded.
+func (r *Response) Decode(v any) *Response {
+	r.t.Helper()
+
+	if err := json.Unmarshal(r.body, v); err != nil {
+		r.t.Fatalf("%s: failed to decode JSON body: %s", r.name, err)
+	}
+
+	return r
+}
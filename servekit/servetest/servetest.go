@@ -0,0 +1,187 @@
+// Package servetest implements the in-process test harness for servekit.ListenerHTTP.
+//
+// The harness serves the listener through ListenerHTTP.ServeListener, so requests pass
+// through the same router, middlewares and server settings as in production, and shuts
+// it down gracefully when the test finishes.
+//
+// Example:
+//
+//	srv := servetest.New(t, func(l *servekit.ListenerHTTP) {
+//		l.Mount("/api", api.Router())
+//	}, servekit.WithHealthCheck())
+//
+//	srv.Get("/api/users/1").Header("Authorization", "secret").Do().
+//		Status(http.StatusOK).
+//		JSON(td.JSON(`{"id": 1, "name": "John"}`))
+package servetest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/heartwilltell/bones/servekit"
+)
+
+// startTimeout represents the time given to the listener to start serving.
+const startTimeout = 5 * time.Second
+
+// Server represents the running servekit.ListenerHTTP under test.
+type Server struct {
+	t testing.TB
+
+	// Listener represents the listener under test.
+	Listener *servekit.ListenerHTTP
+
+	// URL represents the base URL of the listener, e.g. 'http://127.0.0.1:43210'.
+	URL string
+
+	// AdminURL represents the base URL of the admin listener.
+	// Empty if the admin listener is not enabled.
+	AdminURL string
+
+	client *http.Client
+}
+
+// New builds the servekit.ListenerHTTP with the given options, calls the optional setup
+// to register the routes and serves the listener on the ephemeral loopback port.
+// The listener is shut down when the test and all its subtests complete.
+func New(t testing.TB, setup func(l *servekit.ListenerHTTP), options ...servekit.ListenerOption) *Server {
+	t.Helper()
+
+	return Start(t, newListener(t, setup, options...))
+}
+
+// NewInMemory is like New, but serves the listener on the in-memory transport,
+// which does not use the network at all. Note that the admin listener, if enabled,
+// is still served on the network.
+func NewInMemory(t testing.TB, setup func(l *servekit.ListenerHTTP), options ...servekit.ListenerOption) *Server {
+	t.Helper()
+
+	return StartInMemory(t, newListener(t, setup, options...))
+}
+
+// Start serves the already built listener l on the ephemeral loopback port.
+// The listener is shut down when the test and all its subtests complete.
+func Start(t testing.TB, l *servekit.ListenerHTTP) *Server {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("servetest: failed to listen: %s", err)
+	}
+
+	return start(t, l, ln, &http.Transport{DisableKeepAlives: true})
+}
+
+// StartInMemory serves the already built listener l on the in-memory transport.
+// The listener is shut down when the test and all its subtests complete.
+func StartInMemory(t testing.TB, l *servekit.ListenerHTTP) *Server {
+	t.Helper()
+
+	ln := newMemoryListener()
+
+	return start(t, l, ln, &http.Transport{DisableKeepAlives: true, DialContext: ln.DialContext})
+}
+
+// Client returns the HTTP client which sends requests to the listener.
+func (s *Server) Client() *http.Client { return s.client }
+
+// Get returns the GET request to the given path of the listener.
+func (s *Server) Get(path string) *Request { return s.Request(http.MethodGet, path) }
+
+// Post returns the POST request to the given path of the listener.
+func (s *Server) Post(path string) *Request { return s.Request(http.MethodPost, path) }
+
+// Put returns the PUT request to the given path of the listener.
+func (s *Server) Put(path string) *Request { return s.Request(http.MethodPut, path) }
+
+// Patch returns the PATCH request to the given path of the listener.
+func (s *Server) Patch(path string) *Request { return s.Request(http.MethodPatch, path) }
+
+// Delete returns the DELETE request to the given path of the listener.
+func (s *Server) Delete(path string) *Request { return s.Request(http.MethodDelete, path) }
+
+// Request returns the request with the given method to the given path of the listener.
+func (s *Server) Request(method, path string) *Request {
+	return newRequest(s.t, s.client, method, s.URL+path)
+}
+
+// Admin returns the request with the given method to the given path of the admin listener.
+// Fails the test if the admin listener is not enabled.
+func (s *Server) Admin(method, path string) *Request {
+	s.t.Helper()
+
+	if s.AdminURL == "" {
+		s.t.Fatal("servetest: admin listener is not enabled")
+	}
+
+	return newRequest(s.t, s.client, method, s.AdminURL+path)
+}
+
+// newListener builds the listener and calls the setup.
+func newListener(t testing.TB, setup func(l *servekit.ListenerHTTP), options ...servekit.ListenerOption) *servekit.ListenerHTTP {
+	t.Helper()
+
+	l, err := servekit.New("", options...)
+	if err != nil {
+		t.Fatalf("servetest: failed to create listener: %s", err)
+	}
+
+	if setup != nil {
+		setup(l)
+	}
+
+	return l
+}
+
+// start serves the listener l on ln and registers its shutdown in t.Cleanup.
+func start(t testing.TB, l *servekit.ListenerHTTP, ln net.Listener, transport *http.Transport) *Server {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() { done <- l.ServeListener(ctx, ln) }()
+
+	t.Cleanup(func() {
+		transport.CloseIdleConnections()
+		cancel()
+
+		if err := <-done; err != nil {
+			t.Errorf("servetest: listener stopped with error: %s", err)
+		}
+	})
+
+	// The listener records its addresses right before it starts serving.
+	for deadline := time.Now().Add(startTimeout); l.Addr() == nil; {
+		select {
+		case err := <-done:
+			done <- err
+			t.Fatalf("servetest: listener stopped before serving: %v", err)
+
+		default:
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("servetest: listener has not started in time")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	s := Server{
+		t:        t,
+		Listener: l,
+		URL:      "http://" + l.Addr().String(),
+		client:   &http.Client{Transport: transport},
+	}
+
+	if addr := l.AdminAddr(); addr != nil {
+		s.AdminURL = "http://" + addr.String()
+	}
+
+	return &s
+}
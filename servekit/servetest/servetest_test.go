@@ -0,0 +1,87 @@
+package servetest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/heartwilltell/bones/errkit"
+	"github.com/heartwilltell/bones/servekit"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+type echoRequest struct {
+	ID    int    `path:"id"`
+	Token string `header:"X-Token"`
+	Page  int    `query:"page"`
+	Name  string `json:"name"`
+}
+
+type echoResponse struct {
+	ID    int    `json:"id"`
+	Token string `json:"token"`
+	Page  int    `json:"page"`
+	Name  string `json:"name"`
+}
+
+func setup(l *servekit.ListenerHTTP) {
+	api := chi.NewRouter()
+
+	servekit.Handle(api, http.MethodPost, "/echo/{id}", func(_ context.Context, req echoRequest) (echoResponse, error) {
+		if req.ID == 0 {
+			return echoResponse{}, errkit.ErrNotFound
+		}
+
+		return echoResponse(req), nil
+	})
+
+	l.Mount("/api", api)
+}
+
+func TestServer(t *testing.T) {
+	type tcase struct {
+		start func(t testing.TB) *Server
+	}
+
+	tests := map[string]tcase{
+		"Network": {
+			start: func(t testing.TB) *Server {
+				return New(t, setup, servekit.WithHealthCheck(), servekit.WithAdminListener("127.0.0.1:0"))
+			},
+		},
+		"InMemory": {
+			start: func(t testing.TB) *Server {
+				return NewInMemory(t, setup, servekit.WithHealthCheck(), servekit.WithAdminListener("127.0.0.1:0"))
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var srv *Server
+
+			t.Run("Requests", func(t *testing.T) {
+				srv = tc.start(t)
+
+				srv.Post("/api/echo/1").
+					Header("X-Token", "secret").
+					Query("page", "2").
+					JSON(map[string]string{"name": "John"}).
+					Do().
+					Status(http.StatusOK).
+					Header("Content-Type", td.HasPrefix("application/json")).
+					JSON(td.JSON(`{"id": 1, "token": "secret", "page": 2, "name": "John"}`)).
+					JSON(echoResponse{ID: 1, Token: "secret", Page: 2, Name: "John"})
+
+				srv.Post("/api/echo/0").Do().Status(http.StatusNotFound).Body("Not Found\n")
+				srv.Get("/api/echo/1").Do().Status(http.StatusMethodNotAllowed)
+				srv.Admin(http.MethodGet, "/livez").Do().Status(http.StatusOK)
+			})
+
+			// The listener is shut down by the cleanup of the subtest.
+			_, err := srv.Client().Get(srv.URL + "/api/echo/1") //nolint:noctx
+			td.CmpError(t, err)
+		})
+	}
+}
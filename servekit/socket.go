@@ -0,0 +1,124 @@
+package servekit
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// unixAddrPrefix represents the address prefix which
+	// tells the listener to bind to a unix domain socket.
+	unixAddrPrefix = "unix:"
+
+	// listenFDsStart represents the first file descriptor
+	// passed to the process by systemd socket activation.
+	listenFDsStart = 3
+
+	// envListenPID represents the environment variable which holds
+	// the PID of the process the sockets were passed to.
+	envListenPID = "LISTEN_PID"
+
+	// envListenFDs represents the environment variable which holds
+	// the number of file descriptors passed to the process.
+	envListenFDs = "LISTEN_FDS"
+
+	// envListenFDNames represents the environment variable which holds
+	// colon separated names of the passed file descriptors.
+	envListenFDNames = "LISTEN_FDNAMES"
+)
+
+// socketConfig holds the configuration of the listening socket.
+type socketConfig struct {
+	// activation tells the listener to use the socket passed by systemd.
+	activation bool
+
+	// activationName represents the name of the passed socket (see FileDescriptorName=
+	// in systemd.socket). Empty name means the first passed socket.
+	activationName string
+
+	// unixMode represents file permissions of the unix domain socket.
+	unixMode fs.FileMode
+}
+
+// listenUnix binds to the unix domain socket located at path.
+// The stale socket file left from the previous run is removed before binding.
+func listenUnix(path string, mode fs.FileMode) (net.Listener, error) {
+	if path == "" {
+		return nil, errors.New("empty unix socket path")
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Mode().Type() == fs.ModeSocket {
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale unix socket: %w", err)
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			_ = ln.Close()
+			return nil, fmt.Errorf("failed to change unix socket permissions: %w", err)
+		}
+	}
+
+	return ln, nil
+}
+
+// listenActivated returns the listener built from the file descriptor
+// passed to the process by systemd socket activation.
+// See: https://www.freedesktop.org/software/systemd/man/sd_listen_fds.html
+func listenActivated(name string) (net.Listener, error) {
+	if pid := os.Getenv(envListenPID); pid != strconv.Itoa(os.Getpid()) {
+		return nil, fmt.Errorf("socket activation: %s=%q does not match the process", envListenPID, pid)
+	}
+
+	n, err := strconv.Atoi(os.Getenv(envListenFDs))
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("socket activation: no file descriptors passed (%s=%q)",
+			envListenFDs, os.Getenv(envListenFDs),
+		)
+	}
+
+	names := strings.Split(os.Getenv(envListenFDNames), ":")
+
+	for i := 0; i < n; i++ {
+		fdName := ""
+		if i < len(names) {
+			fdName = names[i]
+		}
+
+		if name != "" && fdName != name {
+			continue
+		}
+
+		return fileListener(uintptr(listenFDsStart+i), fdName)
+	}
+
+	return nil, fmt.Errorf("socket activation: file descriptor with name %q not found", name)
+}
+
+// fileListener returns a copy of the network listener corresponding to the open fd.
+func fileListener(fd uintptr, name string) (net.Listener, error) {
+	f := os.NewFile(fd, name)
+	if f == nil {
+		return nil, fmt.Errorf("invalid file descriptor: %d", fd)
+	}
+
+	defer f.Close()
+
+	ln, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("file descriptor %d is not a listener: %w", fd, err)
+	}
+
+	return ln, nil
+}
@@ -0,0 +1,53 @@
+package servekit
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sock")
+
+	ln, err := listenUnix(path, 0o600)
+	td.Require(t).CmpNoError(err)
+
+	info, err := os.Stat(path)
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, info.Mode().Perm(), fs.FileMode(0o600))
+	td.CmpNoError(t, ln.Close())
+
+	_, err = listenUnix("", 0)
+	td.CmpError(t, err)
+}
+
+func TestListenActivated(t *testing.T) {
+	t.Run("PIDMismatch", func(t *testing.T) {
+		t.Setenv(envListenPID, "1")
+		t.Setenv(envListenFDs, "1")
+
+		_, err := listenActivated("")
+		td.CmpContains(t, err, envListenPID)
+	})
+
+	t.Run("NoFDs", func(t *testing.T) {
+		t.Setenv(envListenPID, strconv.Itoa(os.Getpid()))
+		t.Setenv(envListenFDs, "0")
+
+		_, err := listenActivated("")
+		td.CmpContains(t, err, "no file descriptors passed")
+	})
+
+	t.Run("NameNotFound", func(t *testing.T) {
+		t.Setenv(envListenPID, strconv.Itoa(os.Getpid()))
+		t.Setenv(envListenFDs, "1")
+		t.Setenv(envListenFDNames, "http")
+
+		_, err := listenActivated("admin")
+		td.CmpContains(t, err, `"admin" not found`)
+	})
+}
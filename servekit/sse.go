@@ -0,0 +1,246 @@
+package servekit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sseHeartbeatInterval represents the default interval of the Server-Sent Events heartbeats.
+const sseHeartbeatInterval = 15 * time.Second
+
+// ErrInvalidEvent is returned by SSEStream.Send when the event cannot be encoded.
+const ErrInvalidEvent Error = "servekit: invalid server-sent event"
+
+// SSEEvent represents the Server-Sent Event.
+type SSEEvent struct {
+	// ID represents the event ID, which is sent back by
+	// the client in the 'Last-Event-ID' header on reconnect.
+	ID string
+
+	// Event represents the event type. Empty type is handled by the 'message' listener.
+	Event string
+
+	// Data represents the event data. Multiline data is sent as multiple 'data' fields.
+	Data string
+
+	// Retry represents the reconnection time sent to the client. Zero means not set.
+	Retry time.Duration
+}
+
+// SSEConfig represents configuration of the Server-Sent Events stream.
+type SSEConfig struct {
+	retry     time.Duration
+	heartbeat time.Duration
+}
+
+// SSERetry represents an optional function for SSEHandler function.
+// If passed to the SSEHandler, will set the config.retry, which tells
+// the client how long to wait before reconnecting.
+func SSERetry(retry time.Duration) Option[*SSEConfig] {
+	return func(c *SSEConfig) { c.retry = retry }
+}
+
+// SSEHeartbeat represents an optional function for SSEHandler function.
+// If passed to the SSEHandler, will set the config.heartbeat, which is the interval of comments
+// sent to keep the idle stream open through proxies. Zero turns off the heartbeats.
+func SSEHeartbeat(interval time.Duration) Option[*SSEConfig] {
+	return func(c *SSEConfig) { c.heartbeat = interval }
+}
+
+// SSEHandler returns the handler which streams the Server-Sent Events sent by fn.
+// Receives the following options to configure the stream:
+// - SSERetry - to set the client reconnection time.
+// - SSEHeartbeat - to set the interval of heartbeats.
+//
+// The ctx passed to fn is canceled when the client goes away or the listener starts
+// shutting down, and fn is expected to return then. The error returned by fn is reported
+// to the log error hook, since the response status has already been sent.
+//
+// Example:
+//
+//	router.Method(http.MethodGet, "/events", servekit.SSEHandler(func(ctx context.Context, s *servekit.SSEStream) error {
+//		for update := range updates.Since(ctx, s.LastEventID()) {
+//			if err := s.SendJSON(update.ID, "update", update); err != nil {
+//				return err
+//			}
+//		}
+//
+//		return nil
+//	}))
+func SSEHandler(fn func(ctx context.Context, stream *SSEStream) error, options ...Option[*SSEConfig]) http.Handler {
+	cfg := SSEConfig{heartbeat: sseHeartbeatInterval}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	return &sseHandler{fn: fn, cfg: cfg}
+}
+
+type sseHandler struct {
+	fn  func(ctx context.Context, stream *SSEStream) error
+	cfg SSEConfig
+}
+
+func (h *sseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := streamContext(r.Context())
+	defer cancel()
+
+	rc := http.NewResponseController(w)
+
+	// The stream outlives the http.Server WriteTimeout.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		reportError(r, fmt.Errorf("failed to reset write deadline: %w", err))
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	stream := &SSEStream{
+		w:           w,
+		rc:          rc,
+		lastEventID: r.Header.Get("Last-Event-ID"),
+	}
+
+	if h.cfg.retry > 0 {
+		if err := stream.Send(SSEEvent{Retry: h.cfg.retry}); err != nil {
+			reportError(r, err)
+			return
+		}
+	} else if err := stream.flush(); err != nil {
+		reportError(r, err)
+		return
+	}
+
+	if h.cfg.heartbeat > 0 {
+		var wg sync.WaitGroup
+		defer wg.Wait()
+
+		// Stop heartbeats before returning, since the
+		// response writer cannot be used after that.
+		heartbeatCtx, stopHeartbeat := context.WithCancel(ctx)
+		defer stopHeartbeat()
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			stream.heartbeat(heartbeatCtx, h.cfg.heartbeat)
+		}()
+	}
+
+	if err := h.fn(ctx, stream); err != nil {
+		reportError(r, err)
+	}
+}
+
+// SSEStream represents the stream of Server-Sent Events.
+// Its methods are safe for concurrent use.
+type SSEStream struct {
+	mu          sync.Mutex
+	w           http.ResponseWriter
+	rc          *http.ResponseController
+	lastEventID string
+}
+
+// LastEventID returns the ID of the last event received by the client
+// before reconnecting, sent in the 'Last-Event-ID' request header.
+// Use it to resume the stream from the next event.
+func (s *SSEStream) LastEventID() string { return s.lastEventID }
+
+// Send sends the event to the client.
+func (s *SSEStream) Send(event SSEEvent) error {
+	if strings.ContainsAny(event.ID, "\r\n\x00") || strings.ContainsAny(event.Event, "\r\n") {
+		return fmt.Errorf("%w: ID and event type should not contain line breaks", ErrInvalidEvent)
+	}
+
+	var b strings.Builder
+
+	if event.ID != "" {
+		b.WriteString("id: " + event.ID + "\n")
+	}
+
+	if event.Event != "" {
+		b.WriteString("event: " + event.Event + "\n")
+	}
+
+	if event.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatInt(event.Retry.Milliseconds(), 10) + "\n")
+	}
+
+	if event.Data != "" || (event.ID == "" && event.Event == "" && event.Retry == 0) {
+		for _, line := range strings.Split(strings.ReplaceAll(event.Data, "\r\n", "\n"), "\n") {
+			b.WriteString("data: " + line + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+
+	return s.write(b.String())
+}
+
+// SendJSON sends the event with the JSON representation of v as data.
+func (s *SSEStream) SendJSON(id, event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEvent, err)
+	}
+
+	return s.Send(SSEEvent{ID: id, Event: event, Data: string(data)})
+}
+
+// heartbeat sends comments to the client each interval until the ctx is done.
+func (s *SSEStream) heartbeat(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if err := s.write(": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// write writes the encoded event and flushes it to the client.
+func (s *SSEStream) write(encoded string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.w.Write([]byte(encoded)); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+
+	return s.flushLocked()
+}
+
+func (s *SSEStream) flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.flushLocked()
+}
+
+func (s *SSEStream) flushLocked() error {
+	if err := s.rc.Flush(); err != nil {
+		return fmt.Errorf("failed to flush event: %w", err)
+	}
+
+	return nil
+}
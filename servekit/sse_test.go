@@ -0,0 +1,148 @@
+package servekit
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/heartwilltell/bones/servekit/middleware"
+	"github.com/heartwilltell/log"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// startListener serves the listener l on the ephemeral port.
+// Returns the base URL of the listener and the function which stops it.
+func startListener(t *testing.T, l *ListenerHTTP) (string, func() error) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	td.Require(t).CmpNoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- l.ServeListener(ctx, ln) }()
+
+	td.Require(t).True(waitFor(func() bool { return l.Addr() != nil }))
+
+	return "http://" + l.Addr().String(), func() error {
+		cancel()
+		return <-errCh
+	}
+}
+
+func TestSSEStream_Send(t *testing.T) {
+	type tcase struct {
+		event   SSEEvent
+		want    string
+		wantErr error
+	}
+
+	tests := map[string]tcase{
+		"Data":      {event: SSEEvent{Data: "hello"}, want: "data: hello\n\n"},
+		"Empty":     {event: SSEEvent{}, want: "data: \n\n"},
+		"Multiline": {event: SSEEvent{ID: "1", Event: "update", Data: "a\r\nb\nc"}, want: "id: 1\nevent: update\ndata: a\ndata: b\ndata: c\n\n"},
+		"Retry":     {event: SSEEvent{Retry: 1500 * time.Millisecond}, want: "retry: 1500\n\n"},
+		"InvalidID": {event: SSEEvent{ID: "1\n2"}, wantErr: ErrInvalidEvent},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			handler := SSEHandler(func(_ context.Context, s *SSEStream) error {
+				err := s.Send(tc.event)
+				td.Cmp(t, err, td.ErrorIs(tc.wantErr))
+
+				return nil
+			}, SSEHeartbeat(0))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			td.Cmp(t, w.Code, http.StatusOK)
+			td.Cmp(t, w.Header().Get("Content-Type"), "text/event-stream")
+			td.Cmp(t, w.Body.String(), tc.want)
+		})
+	}
+}
+
+func TestSSEHandler(t *testing.T) {
+	// The write timeout is shorter than the stream to make sure it does not cut the stream.
+	l, err := New("", WithWriteTimeout(300*time.Millisecond), WithGlobalMiddlewares(
+		middleware.LoggingMiddleware(log.NewNopLog()),
+		middleware.MetricsMiddleware(),
+	))
+	td.Require(t).CmpNoError(err)
+
+	lastEventID := make(chan string, 1)
+
+	l.Mount("/events", SSEHandler(func(ctx context.Context, s *SSEStream) error {
+		lastEventID <- s.LastEventID()
+
+		for id := 42; ; id++ {
+			select {
+			case <-ctx.Done():
+				return nil
+
+			case <-time.After(100 * time.Millisecond):
+				if err := s.SendJSON(strconv.Itoa(id), "update", map[string]int{"id": id}); err != nil {
+					return err
+				}
+			}
+		}
+	}, SSERetry(3*time.Second), SSEHeartbeat(30*time.Millisecond)))
+
+	url, stop := startListener(t, l)
+
+	req, err := http.NewRequest(http.MethodGet, url+"/events", nil) //nolint:noctx
+	td.Require(t).CmpNoError(err)
+	req.Header.Set("Last-Event-ID", "41")
+
+	resp, err := testClient.Do(req)
+	td.Require(t).CmpNoError(err)
+
+	defer resp.Body.Close()
+
+	td.Cmp(t, resp.Header.Get("Content-Type"), "text/event-stream")
+	td.Cmp(t, <-lastEventID, "41")
+
+	lines := make(chan string)
+
+	go func() {
+		defer close(lines)
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	var received []string
+
+	// The event 45 is sent after the write timeout is expired.
+	for deadline := time.After(5 * time.Second); len(received) == 0 || received[len(received)-1] != "id: 45"; {
+		select {
+		case line := <-lines:
+			received = append(received, line)
+
+		case <-deadline:
+			t.Fatalf("stream is stuck, received: %q", received)
+		}
+	}
+
+	td.Cmp(t, received[0], "retry: 3000")
+	td.Cmp(t, received, td.SuperBagOf(": heartbeat", "id: 42", "event: update", `data: {"id":42}`, "id: 44"))
+
+	// The stream is closed by the listener shutdown without waiting for the shutdown timeout.
+	start := time.Now()
+	td.CmpNoError(t, stop())
+	td.Cmp(t, time.Since(start), td.Lt(time.Second))
+
+	for range lines {
+	}
+}
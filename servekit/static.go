@@ -0,0 +1,379 @@
+package servekit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/heartwilltell/bones/errkit"
+	"github.com/heartwilltell/bones/servekit/respond"
+)
+
+// staticIndex represents the default name of the directory index file.
+const staticIndex = "index.html"
+
+// staticEncodings represents the content encodings of the precompressed
+// files in order of preference, with extensions of the files.
+var staticEncodings = []struct{ encoding, ext string }{
+	{encoding: "br", ext: ".br"},
+	{encoding: "gzip", ext: ".gz"},
+}
+
+// StaticConfig represents configuration of the static files serving.
+type StaticConfig struct {
+	index         string
+	precompressed bool
+	spa           bool
+	spaExclude    []string
+	cacheControl  []staticCacheRule
+}
+
+// staticCacheRule represents the Cache-Control header value for the files matching the pattern.
+type staticCacheRule struct {
+	pattern string
+	value   string
+}
+
+// StaticIndex represents an optional function for MountStatic function.
+// If passed to the MountStatic, will set the config.index, which is the name
+// of the file served for directories and as the SPA fallback. Default is 'index.html'.
+func StaticIndex(name string) Option[*StaticConfig] {
+	return func(c *StaticConfig) { c.index = name }
+}
+
+// StaticPrecompressed represents an optional function for MountStatic function.
+// If passed to the MountStatic, will set the config.precompressed, which turns on serving
+// of the precompressed '.br' and '.gz' variants of the files placed next to them. Default is true.
+func StaticPrecompressed(enable bool) Option[*StaticConfig] {
+	return func(c *StaticConfig) { c.precompressed = enable }
+}
+
+// StaticSPA represents an optional function for MountStatic function.
+// If passed to the MountStatic, will turn on the single page application fallback, which
+// serves the index file for requests to unknown files without an extension, so the client
+// side router can handle them. Requests with the URL path starting with one of the exclude
+// prefixes, e.g. '/api/', are responded with HTTP 404 (Not Found) instead.
+func StaticSPA(exclude ...string) Option[*StaticConfig] {
+	return func(c *StaticConfig) {
+		c.spa = true
+		c.spaExclude = append(c.spaExclude, exclude...)
+	}
+}
+
+// StaticCacheControl represents an optional function for MountStatic function.
+// If passed to the MountStatic, will add the Cache-Control header value for the files matching
+// the pattern to the config.cacheControl. The pattern has the path.Match syntax and is matched
+// against the file path relative to the root of the file system, or against the file name when
+// the pattern has no slashes. The first matching pattern wins.
+//
+// Example:
+//
+//	servekit.StaticCacheControl("assets/*", "public, max-age=31536000, immutable"),
+//	servekit.StaticCacheControl("*.html", "no-cache"),
+func StaticCacheControl(pattern, value string) Option[*StaticConfig] {
+	return func(c *StaticConfig) {
+		c.cacheControl = append(c.cacheControl, staticCacheRule{pattern: pattern, value: value})
+	}
+}
+
+// MountStatic mounts the handler serving files of the fsys on the route.
+// Receives the following options to configure the serving:
+// - StaticIndex - to set the name of the directory index file.
+// - StaticPrecompressed - to turn off serving of the precompressed files.
+// - StaticSPA - to turn on the single page application fallback.
+// - StaticCacheControl - to set the Cache-Control header by the file path.
+//
+// Responses carry the ETag header with the hash of the file content and the Last-Modified
+// header when the modification time of the file is known, and conditional and range requests
+// are handled. Directory listings are not served.
+//
+// Example:
+//
+//	//go:embed dist
+//	var dist embed.FS
+//
+//	ui, _ := fs.Sub(dist, "dist")
+//
+//	err := listener.MountStatic("/", ui,
+//		servekit.StaticSPA("/api/"),
+//		servekit.StaticCacheControl("assets/*", "public, max-age=31536000, immutable"),
+//	)
+func (l *ListenerHTTP) MountStatic(route string, fsys fs.FS, options ...Option[*StaticConfig]) error {
+	cfg := StaticConfig{
+		index:         staticIndex,
+		precompressed: true,
+		spaExclude:    make([]string, 0),
+		cacheControl:  make([]staticCacheRule, 0),
+	}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	if fsys == nil {
+		return errors.New("invalid static file system: should not be nil")
+	}
+
+	if cfg.index == "" || strings.Contains(cfg.index, "/") {
+		return fmt.Errorf("invalid static index: %q (should not be empty or contain '/' slash)", cfg.index)
+	}
+
+	for _, rule := range cfg.cacheControl {
+		if _, err := path.Match(rule.pattern, ""); err != nil {
+			return fmt.Errorf("invalid static cache control pattern: %q: %w", rule.pattern, err)
+		}
+	}
+
+	l.Mount(route, &staticHandler{fsys: fsys, cfg: cfg})
+
+	return nil
+}
+
+type staticHandler struct {
+	fsys fs.FS
+	cfg  StaticConfig
+
+	// etags caches ETag of files by staticFileKey.
+	etags sync.Map
+}
+
+// staticFileKey represents the key of the file ETag, which changes along with the file.
+type staticFileKey struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (h *staticHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		respond.Status(w, r, http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	urlPath := r.URL.Path
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePath != "" {
+		urlPath = rctx.RoutePath
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+urlPath), "/")
+	if name == "" {
+		name = "."
+	}
+
+	info, err := fs.Stat(h.fsys, name)
+
+	switch {
+	case err == nil && info.IsDir():
+		// Relative links of the index file are resolved against the directory.
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			redirectToDir(w, r)
+			return
+		}
+
+		name = path.Join(name, h.cfg.index)
+
+	case errors.Is(err, fs.ErrNotExist) && h.fallback(r, name):
+		name = h.cfg.index
+
+	case err != nil:
+		h.error(w, r, name, err)
+		return
+	}
+
+	h.serveFile(w, r, name)
+}
+
+// fallback reports whether the request to the missing file should be served by the index file.
+func (h *staticHandler) fallback(r *http.Request, name string) bool {
+	if !h.cfg.spa || path.Ext(name) != "" {
+		return false
+	}
+
+	for _, prefix := range h.cfg.spaExclude {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// serveFile serves the file with the given name, or its precompressed variant accepted by the client.
+func (h *staticHandler) serveFile(w http.ResponseWriter, r *http.Request, name string) {
+	header := w.Header()
+
+	if value := h.cacheControl(name); value != "" {
+		header.Set("Cache-Control", value)
+	}
+
+	served := name
+
+	if h.cfg.precompressed {
+		header.Add("Vary", "Accept-Encoding")
+
+		for _, enc := range staticEncodings {
+			if !acceptsEncoding(r, enc.encoding) {
+				continue
+			}
+
+			if info, err := fs.Stat(h.fsys, name+enc.ext); err == nil && info.Mode().IsRegular() {
+				served = name + enc.ext
+				header.Set("Content-Encoding", enc.encoding)
+
+				break
+			}
+		}
+	}
+
+	// The content type is detected by the name of the original file,
+	// since sniffing the content of the precompressed file is useless.
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		header.Set("Content-Type", ctype)
+	} else if served != name {
+		header.Set("Content-Type", "application/octet-stream")
+	}
+
+	f, err := h.fsys.Open(served)
+	if err != nil {
+		h.error(w, r, served, err)
+		return
+	}
+
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		h.error(w, r, served, err)
+		return
+	}
+
+	if !info.Mode().IsRegular() {
+		h.error(w, r, served, fs.ErrNotExist)
+		return
+	}
+
+	etag, err := h.etag(served, info)
+	if err != nil {
+		h.error(w, r, served, err)
+		return
+	}
+
+	header.Set("ETag", etag)
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			h.error(w, r, served, err)
+			return
+		}
+
+		content = bytes.NewReader(data)
+	}
+
+	// ServeContent handles the conditional and range requests, and sets
+	// the Last-Modified header unless the modification time is zero.
+	http.ServeContent(w, r, name, info.ModTime(), content)
+}
+
+// etag returns the ETag of the file, which is the hash of its content.
+func (h *staticHandler) etag(name string, info fs.FileInfo) (string, error) {
+	key := staticFileKey{name: name, size: info.Size(), modTime: info.ModTime()}
+
+	if etag, ok := h.etags.Load(key); ok {
+		return etag.(string), nil
+	}
+
+	f, err := h.fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
+	}
+
+	etag := `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+	h.etags.Store(key, etag)
+
+	return etag, nil
+}
+
+// cacheControl returns the Cache-Control header value for the file with the given name.
+func (h *staticHandler) cacheControl(name string) string {
+	for _, rule := range h.cfg.cacheControl {
+		target := name
+		if !strings.Contains(rule.pattern, "/") {
+			target = path.Base(name)
+		}
+
+		if ok, _ := path.Match(rule.pattern, target); ok {
+			return rule.value
+		}
+	}
+
+	return ""
+}
+
+func (h *staticHandler) error(w http.ResponseWriter, r *http.Request, name string, err error) {
+	// Drop the headers set for the file.
+	for _, key := range []string{"Cache-Control", "Content-Encoding", "Content-Type", "ETag"} {
+		w.Header().Del(key)
+	}
+
+	// Do not expose the presence of files which cannot be accessed.
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) || errors.Is(err, fs.ErrInvalid) {
+		respond.Error(w, r, fmt.Errorf("%w: file %s", errkit.ErrNotFound, name))
+
+		return
+	}
+
+	respond.Error(w, r, fmt.Errorf("failed to serve file %s: %w", name, err))
+}
+
+// redirectToDir redirects the request to the URL path with the trailing slash.
+// The target is relative to the last path segment, like the http.FileServer does,
+// since the absolute target of the '//host' path would redirect to the other host.
+func redirectToDir(w http.ResponseWriter, r *http.Request) {
+	target := path.Base(r.URL.Path) + "/"
+	if r.URL.RawQuery != "" {
+		target += "?" + r.URL.RawQuery
+	}
+
+	w.Header().Set("Location", target)
+	w.WriteHeader(http.StatusMovedPermanently)
+}
+
+// acceptsEncoding reports whether the Accept-Encoding request header accepts the encoding.
+func acceptsEncoding(r *http.Request, encoding string) bool {
+	for _, value := range r.Header.Values("Accept-Encoding") {
+		for _, part := range strings.Split(value, ",") {
+			coding, params, _ := strings.Cut(part, ";")
+			if !strings.EqualFold(strings.TrimSpace(coding), encoding) {
+				continue
+			}
+
+			q, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q=")
+
+			return !ok || strings.Trim(q, "0.") != ""
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,234 @@
+package servekit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestListenerHTTP_MountStatic(t *testing.T) {
+	modTime := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	fsys := fstest.MapFS{
+		"index.html":          {Data: []byte("<html>app</html>"), ModTime: modTime},
+		"assets/app.js":       {Data: []byte("console.log('app')")},
+		"assets/app.js.br":    {Data: []byte("brotli")},
+		"assets/app.js.gz":    {Data: []byte("gzip")},
+		"docs/index.html":     {Data: []byte("<html>docs</html>")},
+		"evil.com/index.html": {Data: []byte("<html>evil</html>")},
+		"robots.txt":          {Data: []byte("User-agent: *")},
+		"assets/style.css.gz": {Data: []byte("gzip")},
+	}
+
+	l, err := New(":0")
+	td.Require(t).CmpNoError(err)
+
+	l.Mount("/api", http.NotFoundHandler())
+
+	td.Require(t).CmpNoError(l.MountStatic("/", fsys,
+		StaticSPA("/api/", "/metrics"),
+		StaticCacheControl("assets/*", "public, max-age=31536000, immutable"),
+		StaticCacheControl("*.html", "no-cache"),
+	))
+
+	do := func(method, target string, header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, nil)
+		for key, values := range header {
+			r.Header[key] = values
+		}
+
+		w := httptest.NewRecorder()
+		l.server.Handler.ServeHTTP(w, r)
+
+		return w
+	}
+
+	type tcase struct {
+		method     string
+		target     string
+		header     http.Header
+		wantStatus int
+		wantBody   string
+		wantHeader map[string]any
+	}
+
+	tests := map[string]tcase{
+		"Index": {
+			target:     "/",
+			wantStatus: http.StatusOK,
+			wantBody:   "<html>app</html>",
+			wantHeader: map[string]any{
+				"Content-Type":  td.HasPrefix("text/html"),
+				"Cache-Control": "no-cache",
+				"Last-Modified": modTime.Format(http.TimeFormat),
+			},
+		},
+		"File": {
+			target:     "/robots.txt",
+			wantStatus: http.StatusOK,
+			wantBody:   "User-agent: *",
+			wantHeader: map[string]any{"Content-Type": td.HasPrefix("text/plain"), "Cache-Control": "", "Last-Modified": ""},
+		},
+		"Head": {
+			method:     http.MethodHead,
+			target:     "/robots.txt",
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]any{"Content-Length": "13"},
+		},
+		"Brotli": {
+			target:     "/assets/app.js",
+			header:     http.Header{"Accept-Encoding": {"gzip, deflate, br"}},
+			wantStatus: http.StatusOK,
+			wantBody:   "brotli",
+			wantHeader: map[string]any{
+				"Content-Encoding": "br",
+				"Content-Type":     td.Contains("javascript"),
+				"Cache-Control":    "public, max-age=31536000, immutable",
+				"Vary":             "Accept-Encoding",
+			},
+		},
+		"Gzip": {
+			target:     "/assets/app.js",
+			header:     http.Header{"Accept-Encoding": {"gzip, br;q=0"}},
+			wantStatus: http.StatusOK,
+			wantBody:   "gzip",
+			wantHeader: map[string]any{"Content-Encoding": "gzip"},
+		},
+		"Identity": {
+			target:     "/assets/app.js",
+			wantStatus: http.StatusOK,
+			wantBody:   "console.log('app')",
+			wantHeader: map[string]any{"Content-Encoding": "", "Vary": "Accept-Encoding"},
+		},
+		"OnlyPrecompressed": {
+			target:     "/assets/style.css",
+			header:     http.Header{"Accept-Encoding": {"gzip"}},
+			wantStatus: http.StatusNotFound,
+			wantHeader: map[string]any{"Content-Encoding": "", "Cache-Control": ""},
+		},
+		"DirectoryRedirect": {
+			target:     "/docs?page=1",
+			wantStatus: http.StatusMovedPermanently,
+			wantHeader: map[string]any{"Location": "docs/?page=1"},
+		},
+		"DirectoryRedirectHost": {
+			target:     "//evil.com",
+			wantStatus: http.StatusMovedPermanently,
+			wantHeader: map[string]any{"Location": "evil.com/"},
+		},
+		"DirectoryIndex": {
+			target:     "/docs/",
+			wantStatus: http.StatusOK,
+			wantBody:   "<html>docs</html>",
+		},
+		"Fallback": {
+			target:     "/users/42",
+			wantStatus: http.StatusOK,
+			wantBody:   "<html>app</html>",
+			wantHeader: map[string]any{"Cache-Control": "no-cache"},
+		},
+		"MissingAsset": {
+			target:     "/assets/missing.js",
+			wantStatus: http.StatusNotFound,
+		},
+		"ExcludedRoute": {
+			target:     "/metrics/custom",
+			wantStatus: http.StatusNotFound,
+		},
+		"APIRoute": {
+			target:     "/api/users",
+			wantStatus: http.StatusNotFound,
+		},
+		"PathTraversal": {
+			target:     "/../../etc/passwd",
+			wantStatus: http.StatusOK,
+			wantBody:   "<html>app</html>",
+		},
+		"MethodNotAllowed": {
+			method:     http.MethodPost,
+			target:     "/robots.txt",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantHeader: map[string]any{"Allow": "GET, HEAD"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tc.method == "" {
+				tc.method = http.MethodGet
+			}
+
+			w := do(tc.method, tc.target, tc.header)
+
+			td.Cmp(t, w.Code, tc.wantStatus)
+
+			if tc.wantBody != "" {
+				td.Cmp(t, w.Body.String(), tc.wantBody)
+			}
+
+			for key, value := range tc.wantHeader {
+				td.Cmp(t, w.Header().Get(key), value, key)
+			}
+		})
+	}
+
+	t.Run("ETag", func(t *testing.T) {
+		w := do(http.MethodGet, "/assets/app.js", nil)
+		etag := w.Header().Get("ETag")
+		td.Cmp(t, etag, td.Re(`^"[0-9a-f]{32}"$`))
+
+		w = do(http.MethodGet, "/assets/app.js", http.Header{"If-None-Match": {etag}})
+		td.Cmp(t, w.Code, http.StatusNotModified)
+
+		// Precompressed variants have their own ETag.
+		w = do(http.MethodGet, "/assets/app.js", http.Header{"Accept-Encoding": {"br"}, "If-None-Match": {etag}})
+		td.Cmp(t, w.Code, http.StatusOK)
+		td.Cmp(t, w.Header().Get("ETag"), td.All(td.Re(`^"[0-9a-f]{32}"$`), td.Not(etag)))
+	})
+
+	t.Run("LastModified", func(t *testing.T) {
+		w := do(http.MethodGet, "/", http.Header{"If-Modified-Since": {modTime.Format(http.TimeFormat)}})
+		td.Cmp(t, w.Code, http.StatusNotModified)
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		w := do(http.MethodGet, "/robots.txt", http.Header{"Range": {"bytes=0-9"}})
+		td.Cmp(t, w.Code, http.StatusPartialContent)
+		td.Cmp(t, w.Body.String(), "User-agent")
+	})
+
+	t.Run("WithoutSPA", func(t *testing.T) {
+		l, err := New(":0")
+		td.Require(t).CmpNoError(err)
+		td.Require(t).CmpNoError(l.MountStatic("/ui", fsys, StaticPrecompressed(false)))
+
+		for target, want := range map[string]int{
+			"/ui":               http.StatusMovedPermanently,
+			"/ui/":              http.StatusOK,
+			"/ui/users/42":      http.StatusNotFound,
+			"/ui/assets/app.js": http.StatusOK,
+		} {
+			r := httptest.NewRequest(http.MethodGet, target, nil)
+			r.Header.Set("Accept-Encoding", "br")
+
+			w := httptest.NewRecorder()
+			l.server.Handler.ServeHTTP(w, r)
+
+			td.Cmp(t, w.Code, want, target)
+			td.Cmp(t, w.Header().Get("Content-Encoding"), "", target)
+		}
+	})
+
+	t.Run("InvalidOptions", func(t *testing.T) {
+		l, err := New(":0")
+		td.Require(t).CmpNoError(err)
+
+		td.CmpContains(t, l.MountStatic("/", nil), "invalid static file system")
+		td.CmpContains(t, l.MountStatic("/", fsys, StaticIndex("")), "invalid static index")
+		td.CmpContains(t, l.MountStatic("/", fsys, StaticCacheControl("[", "no-cache")), "invalid static cache control pattern")
+	})
+}
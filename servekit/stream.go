@@ -0,0 +1,99 @@
+package servekit
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/heartwilltell/bones/ctxkit"
+)
+
+// streamsKey represents the context key of the streams tracker of the listener.
+type streamsKey struct{}
+
+// streams tracks the long-lived streams served by the listener, such as Server-Sent
+// Events and WebSocket connections, to close them when the listener shuts down.
+//
+// Server-Sent Events streams are served by regular handlers, which http.Server.Shutdown
+// waits for, while WebSocket connections are hijacked, so they are waited for separately.
+type streams struct {
+	done      chan struct{}
+	closeOnce sync.Once
+	hijacked  sync.WaitGroup
+}
+
+func newStreams() *streams {
+	return &streams{done: make(chan struct{})}
+}
+
+// close signals the streams to close.
+func (s *streams) close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// wait waits for the hijacked streams to be closed or the ctx to be done.
+func (s *streams) wait(ctx context.Context) error {
+	closed := make(chan struct{})
+
+	go func() {
+		s.hijacked.Wait()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+		return nil
+
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// handler puts the streams tracker to the context of each request.
+func (s *streams) handler(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), streamsKey{}, s)))
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// ShutdownSignal returns the channel which is closed when the listener serving the request
+// with the given ctx starts shutting down. Long-running handlers should return once it is
+// closed, so the listener can be shut down gracefully. Returns nil channel, which is never
+// closed, if the ctx does not belong to the request served by ListenerHTTP.
+func ShutdownSignal(ctx context.Context) <-chan struct{} {
+	if s, ok := ctx.Value(streamsKey{}).(*streams); ok {
+		return s.done
+	}
+
+	return nil
+}
+
+// streamContext returns the copy of the request ctx, which is canceled
+// when the listener serving the request starts shutting down.
+func streamContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	if done := ShutdownSignal(ctx); done != nil {
+		go func() {
+			select {
+			case <-done:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
+
+	return ctx, cancel
+}
+
+// reportError reports the error of the stream to the log error hook,
+// since the response status has already been sent to the client.
+func reportError(r *http.Request, err error) {
+	// Get log hook from the context to set an error which
+	// will be logged along with access log line.
+	if hook := ctxkit.GetLogErrHook(r.Context()); hook != nil {
+		hook(err)
+	}
+}
@@ -0,0 +1,156 @@
+package servekit
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/VictoriaMetrics/metrics"
+	"github.com/heartwilltell/log"
+)
+
+const (
+	// tlsReloadSuccessMetric counts successful TLS certificate reloads.
+	tlsReloadSuccessMetric = `tls_certificate_reloads_total{status="success"}`
+
+	// tlsReloadFailureMetric counts TLS certificate reloads refused due to an invalid keypair.
+	tlsReloadFailureMetric = `tls_certificate_reloads_total{status="failure"}`
+)
+
+// certReloader holds the TLS keypair loaded from the certificate and key files,
+// and atomically swaps it when the files are changed on disk.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	logger   log.Logger
+
+	cert    atomic.Pointer[tls.Certificate]
+	version string
+}
+
+// newCertReloader returns a pointer to a new instance of certReloader
+// with keypair loaded from the given certFile and keyFile.
+func newCertReloader(certFile, keyFile string, logger log.Logger) (*certReloader, error) {
+	r := certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		logger:   logger,
+	}
+
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// GetCertificate implements the tls.Config GetCertificate function.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// watch polls the certificate and key files with the given interval,
+// and reloads the keypair when the files are changed.
+// Blocks until the ctx is canceled.
+func (r *certReloader) watch(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-ticker.C:
+			reloaded, err := r.reload()
+			if err != nil {
+				metrics.GetOrCreateCounter(tlsReloadFailureMetric).Inc()
+				r.logger.Error("Failed to reload TLS certificate, keep serving the previous one: %s", err.Error())
+
+				continue
+			}
+
+			if reloaded {
+				metrics.GetOrCreateCounter(tlsReloadSuccessMetric).Inc()
+				r.logger.Info("TLS certificate reloaded: %s (expires at %s)",
+					r.certFile, r.cert.Load().Leaf.NotAfter.Format(time.RFC3339),
+				)
+			}
+		}
+	}
+}
+
+// reload loads and validates the keypair if the files has been changed
+// since the last load. The current keypair stays untouched if the new one is invalid.
+func (r *certReloader) reload() (bool, error) {
+	version, err := filesVersion(r.certFile, r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	if version == r.version {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("invalid TLS keypair: %w", err)
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return false, fmt.Errorf("invalid TLS certificate: %w", err)
+	}
+
+	if time.Now().After(leaf.NotAfter) {
+		return false, fmt.Errorf("invalid TLS certificate: expired at %s", leaf.NotAfter.Format(time.RFC3339))
+	}
+
+	cert.Leaf = leaf
+
+	r.cert.Store(&cert)
+	r.version = version
+
+	return true, nil
+}
+
+// loadCertPool returns the certificate pool with
+// certificates from the PEM encoded bundle file.
+func loadCertPool(bundle string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in %s", bundle)
+	}
+
+	return pool, nil
+}
+
+// filesVersion returns the string which changes each time any of the given files is modified.
+func filesVersion(files ...string) (string, error) {
+	var version string
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", err
+		}
+
+		if info.IsDir() {
+			return "", errors.New(file + " is a directory")
+		}
+
+		version += fmt.Sprintf("%s:%d:%d;", file, info.Size(), info.ModTime().UnixNano())
+	}
+
+	return version, nil
+}
@@ -0,0 +1,266 @@
+package servekit
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/heartwilltell/log"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+
+	writeTestKeyPair(t, certFile, keyFile, "first", time.Now().Add(time.Hour))
+
+	r, err := newCertReloader(certFile, keyFile, log.NewNopLog())
+	td.Require(t).CmpNoError(err)
+
+	cert, err := r.GetCertificate(nil)
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, cert.Leaf.Subject.CommonName, "first")
+
+	t.Run("Unchanged", func(t *testing.T) {
+		reloaded, err := r.reload()
+		td.CmpNoError(t, err)
+		td.CmpFalse(t, reloaded)
+	})
+
+	t.Run("Changed", func(t *testing.T) {
+		writeTestKeyPair(t, certFile, keyFile, "second", time.Now().Add(time.Hour))
+		touch(t, certFile, keyFile)
+
+		reloaded, err := r.reload()
+		td.CmpNoError(t, err)
+		td.CmpTrue(t, reloaded)
+
+		cert, _ := r.GetCertificate(nil)
+		td.Cmp(t, cert.Leaf.Subject.CommonName, "second")
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		td.Require(t).CmpNoError(os.WriteFile(keyFile, []byte("garbage"), 0o600))
+		touch(t, keyFile)
+
+		_, err := r.reload()
+		td.CmpError(t, err)
+
+		cert, _ := r.GetCertificate(nil)
+		td.Cmp(t, cert.Leaf.Subject.CommonName, "second")
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		writeTestKeyPair(t, certFile, keyFile, "expired", time.Now().Add(-time.Hour))
+		touch(t, certFile, keyFile)
+
+		_, err := r.reload()
+		td.CmpContains(t, err, "expired")
+
+		cert, _ := r.GetCertificate(nil)
+		td.Cmp(t, cert.Leaf.Subject.CommonName, "second")
+	})
+}
+
+func TestListenerHTTP_ServeTLS_Reload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+
+	writeTestKeyPair(t, certFile, keyFile, "first", time.Now().Add(time.Hour))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	td.Require(t).CmpNoError(err)
+
+	l, err := New(ln.Addr().String(), WithTLSReload(TLSReloadInterval(10*time.Millisecond)))
+	td.Require(t).CmpNoError(err)
+	td.Require(t).CmpNoError(ln.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- l.ServeTLS(ctx, certFile, keyFile) }()
+
+	td.Require(t).True(waitFor(func() bool { return l.Addr() != nil }))
+	td.Cmp(t, peerCommonName(t, l.Addr().String()), "first")
+
+	writeTestKeyPair(t, certFile, keyFile, "second", time.Now().Add(time.Hour))
+	touch(t, certFile, keyFile)
+
+	td.CmpTrue(t, waitFor(func() bool { return peerCommonName(t, l.Addr().String()) == "second" }))
+
+	cancel()
+	td.CmpNoError(t, <-errCh)
+}
+
+func TestListenerHTTP_ServeTLS_ReloadInvalidInterval(t *testing.T) {
+	_, err := New(":0", WithTLSReload(TLSReloadInterval(0)))
+	td.CmpContains(t, err, "invalid TLS reload interval")
+}
+
+// peerCommonName returns the common name of the certificate served on addr.
+func peerCommonName(t *testing.T, addr string) string {
+	t.Helper()
+
+	client := http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+		DisableKeepAlives: true,
+	}}
+
+	resp, err := client.Get("https://" + addr) //nolint:noctx
+	td.Require(t).CmpNoError(err)
+
+	defer resp.Body.Close()
+
+	return resp.TLS.PeerCertificates[0].Subject.CommonName
+}
+
+// writeTestKeyPair writes a self-signed certificate with
+// the given common name and expiration to the given files.
+func writeTestKeyPair(t *testing.T, certFile, keyFile, cn string, notAfter time.Time) {
+	t.Helper()
+
+	certPEM, keyPEM := newTestKeyPair(t, cn, notAfter, nil, nil)
+
+	td.Require(t).CmpNoError(os.WriteFile(certFile, certPEM, 0o600))
+	td.Require(t).CmpNoError(os.WriteFile(keyFile, keyPEM, 0o600))
+}
+
+// newTestKeyPair returns PEM encoded certificate and key with the given common name and
+// expiration. The certificate is signed by parent, or self-signed when parent is nil.
+func newTestKeyPair(t *testing.T, cn string, notAfter time.Time, parent *tls.Certificate, modify func(c *x509.Certificate)) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	td.Require(t).CmpNoError(err)
+
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-2 * time.Hour),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+
+	if modify != nil {
+		modify(&template)
+	}
+
+	issuer, signer := &template, any(key)
+
+	if parent != nil {
+		issuer, signer = parent.Leaf, parent.PrivateKey
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, issuer, &key.PublicKey, signer)
+	td.Require(t).CmpNoError(err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	td.Require(t).CmpNoError(err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// touch moves modification time of the files forward to make the change visible.
+func touch(t *testing.T, files ...string) {
+	t.Helper()
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		td.Require(t).CmpNoError(err)
+
+		mtime := info.ModTime().Add(time.Second)
+		td.Require(t).CmpNoError(os.Chtimes(file, mtime, mtime))
+	}
+}
+
+func TestListenerHTTP_ServeTLS_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	caFile, caKeyFile := filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key")
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+
+	writeTestKeyPair(t, caFile, caKeyFile, "ca", time.Now().Add(time.Hour))
+	writeTestKeyPair(t, certFile, keyFile, "server", time.Now().Add(time.Hour))
+
+	ca, err := tls.LoadX509KeyPair(caFile, caKeyFile)
+	td.Require(t).CmpNoError(err)
+
+	ca.Leaf, err = x509.ParseCertificate(ca.Certificate[0])
+	td.Require(t).CmpNoError(err)
+
+	clientCertPEM, clientKeyPEM := newTestKeyPair(t, "client", time.Now().Add(time.Hour), &ca, func(c *x509.Certificate) {
+		c.KeyUsage = x509.KeyUsageDigitalSignature
+	})
+
+	clientCert, err := tls.X509KeyPair(clientCertPEM, clientKeyPEM)
+	td.Require(t).CmpNoError(err)
+
+	t.Run("InvalidBundle", func(t *testing.T) {
+		_, err := New(":0", WithMutualTLS(keyFile))
+		td.CmpContains(t, err, "invalid mutual TLS CA bundle")
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	td.Require(t).CmpNoError(err)
+
+	l, err := New(ln.Addr().String(), WithMutualTLS(caFile))
+	td.Require(t).CmpNoError(err)
+	td.Require(t).CmpNoError(ln.Close())
+
+	l.Mount("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.TLS.VerifiedChains[0][0].Subject.CommonName))
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- l.ServeTLS(ctx, certFile, keyFile) }()
+
+	td.Require(t).True(waitFor(func() bool { return l.Addr() != nil }))
+
+	request := func(certs ...tls.Certificate) (*http.Response, error) {
+		client := http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true, //nolint:gosec
+				Certificates:       certs,
+			},
+			DisableKeepAlives: true,
+		}}
+
+		return client.Get("https://" + l.Addr().String()) //nolint:noctx
+	}
+
+	_, err = request()
+	td.CmpError(t, err)
+
+	resp, err := request(clientCert)
+	td.Require(t).CmpNoError(err)
+
+	body, err := io.ReadAll(resp.Body)
+	td.CmpNoError(t, err)
+	td.CmpNoError(t, resp.Body.Close())
+	td.Cmp(t, string(body), "client")
+
+	cancel()
+	td.CmpNoError(t, <-errCh)
+}
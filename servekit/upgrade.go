@@ -0,0 +1,140 @@
+package servekit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// envUpgradePPID represents the environment variable which holds
+	// the PID of the process which handed off the listeners.
+	envUpgradePPID = "SERVEKIT_UPGRADE_PPID"
+
+	// envUpgradeFDs represents the environment variable which holds colon
+	// separated names of the listeners handed off by the parent process.
+	envUpgradeFDs = "SERVEKIT_UPGRADE_FDS"
+
+	// upgradeReadyFD represents the file descriptor of the pipe the new process
+	// writes to when it becomes ready. The handed off listeners follow it.
+	upgradeReadyFD = 3
+)
+
+// namedListener represents the bound listener along with its name,
+// which is used to find the listener in the new process.
+type namedListener struct {
+	name  string
+	ln    *handoffListener
+	stats *serverStats
+}
+
+// handoffListener wraps the listener which can be handed off to the new process, to stop
+// accepting connections when the new process serves them, without closing the shared socket.
+type handoffListener struct {
+	net.Listener
+
+	paused    atomic.Bool
+	parked    chan struct{}
+	parkOnce  sync.Once
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newHandoffListener(ln net.Listener) *handoffListener {
+	return &handoffListener{Listener: ln, parked: make(chan struct{}), closed: make(chan struct{})}
+}
+
+// Accept waits for and returns the next connection. When the listener is
+// paused, Accept blocks until the listener is closed by the server shutdown.
+func (l *handoffListener) Accept() (net.Conn, error) {
+	if l.paused.Load() {
+		return nil, l.park()
+	}
+
+	conn, err := l.Listener.Accept()
+	if err != nil && l.paused.Load() {
+		return nil, l.park()
+	}
+
+	return conn, err
+}
+
+// park blocks the paused listener until it is closed. Since the server calls Accept
+// after it starts tracking the previously accepted connection, the parked listener
+// tells that all connections accepted before the pause are tracked.
+func (l *handoffListener) park() error {
+	l.parkOnce.Do(func() { close(l.parked) })
+	<-l.closed
+
+	return net.ErrClosed
+}
+
+// Close closes the listener.
+func (l *handoffListener) Close() error {
+	l.closeOnce.Do(func() { close(l.closed) })
+	return l.Listener.Close()
+}
+
+// pause stops accepting connections. The pending Accept is interrupted by the deadline,
+// which is internal to the process and does not affect the socket shared with the new process.
+func (l *handoffListener) pause() {
+	l.paused.Store(true)
+
+	if d, ok := l.Listener.(interface{ SetDeadline(t time.Time) error }); ok {
+		_ = d.SetDeadline(time.Now())
+	}
+}
+
+// handOff stops accepting connections on the listeners, since the new process accepts them, and
+// waits until the request is read from each of accepted connections or the ctx is done. Otherwise,
+// the server shutdown would close the connections which requests have not been read yet.
+func handOff(ctx context.Context, listeners []namedListener) {
+	for _, nl := range listeners {
+		nl.ln.pause()
+	}
+
+	for _, nl := range listeners {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-nl.ln.parked:
+		}
+	}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for _, nl := range listeners {
+		for nl.stats.count(http.StateNew) > 0 {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-ticker.C:
+			}
+		}
+	}
+}
+
+// upgradeEnviron returns the environment of the current process
+// without the variables set by the previous upgrade.
+func upgradeEnviron() []string {
+	env := os.Environ()
+	filtered := make([]string, 0, len(env)+2)
+
+	for _, kv := range env {
+		if strings.HasPrefix(kv, envUpgradePPID+"=") || strings.HasPrefix(kv, envUpgradeFDs+"=") {
+			continue
+		}
+
+		filtered = append(filtered, kv)
+	}
+
+	return filtered
+}
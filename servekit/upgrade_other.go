@@ -0,0 +1,22 @@
+//go:build !unix
+
+package servekit
+
+import (
+	"context"
+	"net"
+)
+
+// upgradeSupported tells whether the zero-downtime binary upgrade is supported on the platform.
+const upgradeSupported = false
+
+// inheritedListener always returns nil, since the upgrade is not supported.
+func inheritedListener(string) (net.Listener, error) { return nil, nil }
+
+// notifyUpgradeReady does nothing, since the upgrade is not supported.
+func notifyUpgradeReady() error { return nil }
+
+// watchUpgrade does nothing, since the upgrade is not supported.
+func (*ListenerHTTP) watchUpgrade(context.Context, context.CancelFunc, []namedListener) error {
+	return nil
+}
@@ -0,0 +1,245 @@
+//go:build unix
+
+package servekit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// upgradeSupported tells whether the zero-downtime binary upgrade is supported on the platform.
+const upgradeSupported = true
+
+// handoff represents the listeners handed off by the parent process during the upgrade.
+type handoff struct {
+	mu        sync.Mutex
+	listeners map[string]*os.File
+	ready     *os.File
+}
+
+// inherited returns the handoff of the current process,
+// which is read from the environment only once.
+var inherited = sync.OnceValue(func() *handoff {
+	h := handoff{listeners: make(map[string]*os.File)}
+
+	if os.Getenv(envUpgradePPID) != strconv.Itoa(os.Getppid()) {
+		return &h
+	}
+
+	names := strings.Split(os.Getenv(envUpgradeFDs), ":")
+
+	h.ready = os.NewFile(upgradeReadyFD, "upgrade-ready")
+
+	for i, name := range names {
+		h.listeners[name] = os.NewFile(uintptr(upgradeReadyFD+1+i), name)
+	}
+
+	// Do not leak the handoff to processes started by the current one.
+	_ = os.Unsetenv(envUpgradePPID)
+	_ = os.Unsetenv(envUpgradeFDs)
+
+	return &h
+})
+
+// inheritedListener returns the listener with the given name handed off by the parent
+// process during the upgrade. Returns nil if there is no such listener.
+func inheritedListener(name string) (net.Listener, error) {
+	h := inherited()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	f, ok := h.listeners[name]
+	if !ok {
+		return nil, nil
+	}
+
+	delete(h.listeners, name)
+
+	ln, err := net.FileListener(f)
+	_ = f.Close()
+
+	if err != nil {
+		return nil, fmt.Errorf("upgrade: handed off %s listener is invalid: %w", name, err)
+	}
+
+	return ln, nil
+}
+
+// notifyUpgradeReady tells the parent process that the
+// handed off listeners are served by the current process.
+func notifyUpgradeReady() error {
+	h := inherited()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.ready == nil {
+		return nil
+	}
+
+	defer func() { h.ready = nil }()
+	defer h.ready.Close()
+
+	if _, err := h.ready.Write([]byte{1}); err != nil {
+		return fmt.Errorf("upgrade: %w", err)
+	}
+
+	return nil
+}
+
+// watchUpgrade upgrades the binary on each SIGUSR2 signal until the ctx is done.
+// When the new process takes over the listeners, stop is called to shut down the listener.
+func (l *ListenerHTTP) watchUpgrade(ctx context.Context, stop context.CancelFunc, listeners []namedListener) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR2)
+
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-signals:
+			l.logger.Info("ListenerHTTP received upgrade signal")
+
+			pid, err := l.startUpgrade(listeners)
+			if err != nil {
+				l.logger.Error("ListenerHTTP failed to upgrade: %s", err.Error())
+				continue
+			}
+
+			l.logger.Info("ListenerHTTP handed off listeners to process %d", pid)
+
+			handoffCtx, cancel := context.WithTimeout(ctx, l.shutdown.timeout)
+			handOff(handoffCtx, listeners)
+			cancel()
+
+			stop()
+
+			return nil
+		}
+	}
+}
+
+// startUpgrade starts the new process of the binary, hands off the listeners to it
+// and waits until it starts serving. Returns the PID of the new process.
+func (l *ListenerHTTP) startUpgrade(listeners []namedListener) (int, error) {
+	binary := l.upgrade.binary
+	args := l.upgrade.args
+
+	if binary == "" {
+		executable, err := os.Executable()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get executable: %w", err)
+		}
+
+		binary, args = executable, os.Args[1:]
+	}
+
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return 0, fmt.Errorf("failed to create readiness pipe: %w", err)
+	}
+
+	defer readyR.Close()
+
+	files := []*os.File{readyW}
+	names := make([]string, 0, len(listeners))
+
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+
+	for _, nl := range listeners {
+		f, err := listenerFile(nl.ln.Listener)
+		if err != nil {
+			return 0, fmt.Errorf("failed to hand off %s listener: %w", nl.name, err)
+		}
+
+		files = append(files, f)
+		names = append(names, nl.name)
+	}
+
+	cmd := exec.Command(binary, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(upgradeEnviron(),
+		envUpgradePPID+"="+strconv.Itoa(os.Getpid()),
+		envUpgradeFDs+"="+strings.Join(names, ":"),
+	)
+
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start new process: %w", err)
+	}
+
+	// Close the write end, so the read fails when the new process exits.
+	_ = readyW.Close()
+
+	if err := waitUpgradeReady(readyR, l.upgrade.timeout); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+
+		return 0, fmt.Errorf("new process %d: %w", cmd.Process.Pid, err)
+	}
+
+	// Unix domain sockets should be kept for the new process.
+	for _, nl := range listeners {
+		if ul, ok := nl.ln.Listener.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+
+	pid := cmd.Process.Pid
+	_ = cmd.Process.Release()
+
+	return pid, nil
+}
+
+// waitUpgradeReady waits until the new process writes to the readiness pipe.
+func waitUpgradeReady(ready *os.File, timeout time.Duration) error {
+	if err := ready.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return fmt.Errorf("failed to set readiness timeout: %w", err)
+	}
+
+	if _, err := ready.Read(make([]byte, 1)); err != nil {
+		switch {
+		case errors.Is(err, io.EOF):
+			return errors.New("exited before serving")
+
+		case errors.Is(err, os.ErrDeadlineExceeded):
+			return fmt.Errorf("not serving after %s", timeout)
+
+		default:
+			return fmt.Errorf("failed to wait for readiness: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// listenerFile returns a duplicate of the file descriptor of the listener.
+func listenerFile(ln net.Listener) (*os.File, error) {
+	filer, ok := ln.(interface{ File() (*os.File, error) })
+	if !ok {
+		return nil, fmt.Errorf("listener %T has no file descriptor", ln)
+	}
+
+	return filer.File()
+}
@@ -0,0 +1,224 @@
+//go:build unix
+
+package servekit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// envTestUpgradeChild represents the environment variable
+// which tells the test binary to run as the upgraded process.
+const envTestUpgradeChild = "SERVEKIT_TEST_UPGRADE_CHILD"
+
+// upgradeTestListener returns the listener served by both the parent and the upgraded process.
+func upgradeTestListener(t *testing.T, options ...Option[*config]) *ListenerHTTP {
+	t.Helper()
+
+	options = append(options, WithAdminListener("127.0.0.1:0"), WithHealthCheck())
+
+	l, err := New("127.0.0.1:0", options...)
+	td.Require(t).CmpNoError(err)
+
+	l.Mount("/", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, os.Getpid())
+	}))
+
+	return l
+}
+
+// TestUpgradeChild runs as the new process started by TestListenerHTTP_Upgrade.
+func TestUpgradeChild(t *testing.T) {
+	if os.Getenv(envTestUpgradeChild) == "" {
+		t.Skip("runs as the upgraded process only")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+	defer cancel()
+
+	if err := upgradeTestListener(t).Serve(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Exit right away to not mix the test output of the process with the parent one.
+	os.Exit(0)
+}
+
+func TestListenerHTTP_Upgrade(t *testing.T) {
+	t.Setenv(envTestUpgradeChild, "1")
+
+	// Catch the upgrade signal, so it does not terminate the test process before the
+	// listener is watching it. The signal is not released, since the last signals sent
+	// might be delivered after the test is done.
+	signal.Notify(make(chan os.Signal, 1), syscall.SIGUSR2)
+
+	l := upgradeTestListener(t, WithUpgrade(
+		UpgradeCommand(os.Args[0], "-test.run=^TestUpgradeChild$"),
+		UpgradeTimeout(10*time.Second),
+	))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	td.Require(t).CmpNoError(err)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- l.ServeListener(context.Background(), ln) }()
+
+	td.Require(t).True(waitFor(func() bool { return l.AdminAddr() != nil }))
+
+	url := "http://" + l.Addr().String()
+	adminURL := "http://" + l.AdminAddr().String()
+
+	get := func(url string) (int, string, error) {
+		resp, err := testClient.Get(url) //nolint:noctx
+		if err != nil {
+			return 0, "", err
+		}
+
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+
+		return resp.StatusCode, string(body), err
+	}
+
+	// Requests are served without errors during the upgrade.
+	var (
+		requests atomic.Int64
+		failures atomic.Int64
+	)
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		for {
+			select {
+			case <-done:
+				return
+
+			default:
+				requests.Add(1)
+
+				if status, _, err := get(url); err != nil || status != http.StatusOK {
+					failures.Add(1)
+				}
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	timeout := time.After(20 * time.Second)
+
+wait:
+	for {
+		select {
+		case err := <-errCh:
+			td.CmpNoError(t, err)
+			break wait
+
+		case <-ticker.C:
+			td.Require(t).CmpNoError(syscall.Kill(os.Getpid(), syscall.SIGUSR2))
+
+		case <-timeout:
+			t.Fatal("listener is not upgraded")
+		}
+	}
+
+	close(done)
+	<-stopped
+
+	td.Cmp(t, requests.Load(), td.Gt(int64(0)))
+	td.Cmp(t, failures.Load(), int64(0))
+
+	status, body, err := get(url)
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, status, http.StatusOK)
+	td.Cmp(t, body, td.Not(strconv.Itoa(os.Getpid())))
+
+	pid, err := strconv.Atoi(body)
+	td.Require(t).CmpNoError(err)
+
+	defer func() { _ = syscall.Kill(pid, syscall.SIGTERM) }()
+
+	status, _, err = get(adminURL + "/health")
+	td.Require(t).CmpNoError(err)
+	td.Cmp(t, status, http.StatusOK)
+
+	// The upgraded process is shut down as usual.
+	td.Require(t).CmpNoError(syscall.Kill(pid, syscall.SIGTERM))
+	td.CmpTrue(t, waitFor(func() bool {
+		_, _, err := get(url)
+		return err != nil
+	}))
+}
+
+func TestListenerHTTP_startUpgrade(t *testing.T) {
+	type tcase struct {
+		command []string
+		timeout time.Duration
+		wantErr string
+	}
+
+	tests := map[string]tcase{
+		"Exited":  {command: []string{"/bin/sh", "-c", "exit 1"}, timeout: 10 * time.Second, wantErr: "exited before serving"},
+		"Timeout": {command: []string{"sleep", "10"}, timeout: 100 * time.Millisecond, wantErr: "not serving after 100ms"},
+		"Missing": {command: []string{"/nonexistent/binary"}, timeout: time.Second, wantErr: "failed to start new process"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, err := New("127.0.0.1:0", WithUpgrade(UpgradeCommand(tc.command[0], tc.command[1:]...), UpgradeTimeout(tc.timeout)))
+			td.Require(t).CmpNoError(err)
+
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			td.Require(t).CmpNoError(err)
+
+			defer ln.Close()
+
+			start := time.Now()
+
+			_, err = l.startUpgrade([]namedListener{{name: mainListenerName, ln: newHandoffListener(ln), stats: l.stats}})
+			td.CmpContains(t, err, tc.wantErr)
+			td.Cmp(t, time.Since(start), td.Lt(5*time.Second))
+
+			// The listener is kept open.
+			conn, err := net.Dial("tcp", ln.Addr().String())
+			td.Require(t).CmpNoError(err)
+			conn.Close()
+		})
+	}
+
+	t.Run("InvalidOptions", func(t *testing.T) {
+		_, err := New(":0", WithUpgrade(UpgradeTimeout(0)))
+		td.CmpContains(t, err, "invalid upgrade timeout")
+
+		_, err = New(":0", WithUpgrade(), WithHTTP3())
+		td.CmpContains(t, err, "not compatible with HTTP/3")
+	})
+
+	t.Run("NoHandoff", func(t *testing.T) {
+		ln, err := inheritedListener(mainListenerName)
+		td.CmpNoError(t, err)
+		td.CmpNil(t, ln)
+		td.CmpNoError(t, notifyUpgradeReady())
+		td.Cmp(t, strings.Join(upgradeEnviron(), "\n"), td.Not(td.Contains(envUpgradePPID)))
+	})
+}
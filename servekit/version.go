@@ -0,0 +1,257 @@
+package servekit
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/heartwilltell/bones/ctxkit"
+)
+
+const (
+	// apiVersionHeader represents the response header which holds the version of the API served the request.
+	apiVersionHeader = "API-Version"
+
+	// versionParam represents the media type parameter which requests the version of the API.
+	versionParam = "version"
+)
+
+// APIVersion represents the version of the API mounted by ListenerHTTP.MountVersions.
+type APIVersion struct {
+	name        string
+	handler     http.Handler
+	middlewares []Middleware
+	deprecation time.Time
+	sunset      time.Time
+	link        string
+}
+
+// Version returns the version of the API with the given name, e.g. 'v1', served by the handler.
+// Receives the following options to configure the version:
+// - VersionMiddlewares - to apply middlewares to the version.
+// - VersionDeprecated - to mark the version as deprecated.
+// - VersionSunset - to announce when the version stops being served.
+// - VersionLink - to link the documentation of the deprecation.
+func Version(name string, handler http.Handler, options ...Option[*APIVersion]) *APIVersion {
+	v := APIVersion{
+		name:        name,
+		handler:     handler,
+		middlewares: make([]Middleware, 0),
+	}
+
+	for _, opt := range options {
+		opt(&v)
+	}
+
+	return &v
+}
+
+// VersionMiddlewares represents an optional function for Version function.
+// If passed to the Version, will add the given middlewares to the version.middlewares.
+func VersionMiddlewares(middlewares ...Middleware) Option[*APIVersion] {
+	return func(v *APIVersion) { v.middlewares = append(v.middlewares, middlewares...) }
+}
+
+// VersionDeprecated represents an optional function for Version function.
+// If passed to the Version, will set the version.deprecation, which is sent
+// in the 'Deprecation' response header (RFC 9745).
+func VersionDeprecated(at time.Time) Option[*APIVersion] {
+	return func(v *APIVersion) { v.deprecation = at }
+}
+
+// VersionSunset represents an optional function for Version function.
+// If passed to the Version, will set the version.sunset, which is sent
+// in the 'Sunset' response header (RFC 8594).
+func VersionSunset(at time.Time) Option[*APIVersion] {
+	return func(v *APIVersion) { v.sunset = at }
+}
+
+// VersionLink represents an optional function for Version function.
+// If passed to the Version, will set the version.link, which is sent in the 'Link'
+// response header with 'deprecation' relation type to describe the deprecation.
+func VersionLink(link string) Option[*APIVersion] {
+	return func(v *APIVersion) { v.link = link }
+}
+
+// VersioningConfig represents configuration of the version negotiation.
+type VersioningConfig struct {
+	accept         bool
+	vendor         string
+	defaultVersion string
+}
+
+// VersionByAccept represents an optional function for MountVersions function.
+// If passed to the MountVersions, the version is negotiated by the Accept request
+// header instead of the route prefix. The version is requested either by the vendor
+// media type, e.g. 'application/vnd.{vendor}.v2+json', or by the 'version'
+// media type parameter, e.g. 'application/json; version=v2'.
+func VersionByAccept(vendor string) Option[*VersioningConfig] {
+	return func(c *VersioningConfig) {
+		c.accept = true
+		c.vendor = vendor
+	}
+}
+
+// VersionDefault represents an optional function for MountVersions function.
+// If passed to the MountVersions, will set the version which serves requests
+// without the requested version when the version is negotiated by VersionByAccept.
+// By default, the last of the versions is used.
+func VersionDefault(name string) Option[*VersioningConfig] {
+	return func(c *VersioningConfig) { c.defaultVersion = name }
+}
+
+// MountVersions mounts the given versions of the API on the route.
+//
+// By default, each version is mounted on the route prefixed by its name, e.g. '/api/v1'
+// and '/api/v2'. Pass VersionByAccept to negotiate the version by the Accept request header
+// and serve all the versions on the same route. Requests for an unknown version are
+// responded with HTTP 406 (Not Acceptable).
+//
+// Responses carry the 'API-Version' header with the name of the version which served
+// the request, and the 'Deprecation', 'Sunset' and 'Link' headers for deprecated versions.
+func (l *ListenerHTTP) MountVersions(route string, versions []*APIVersion, options ...Option[*VersioningConfig]) error {
+	cfg := VersioningConfig{}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	if len(versions) == 0 {
+		return errors.New("invalid API versions: should not be empty")
+	}
+
+	byName := make(map[string]http.Handler, len(versions))
+
+	for _, v := range versions {
+		if v.name == "" || strings.Contains(v.name, "/") {
+			return fmt.Errorf("invalid API version name: %q (should not be empty or contain '/' slash)", v.name)
+		}
+
+		if v.handler == nil {
+			return fmt.Errorf("invalid API version %s: handler is nil", v.name)
+		}
+
+		if _, ok := byName[v.name]; ok {
+			return fmt.Errorf("invalid API version %s: duplicated", v.name)
+		}
+
+		byName[v.name] = v.versionHandler()
+	}
+
+	if !cfg.accept {
+		for _, v := range versions {
+			l.Mount(path.Join(route, v.name), byName[v.name])
+		}
+
+		return nil
+	}
+
+	if cfg.vendor == "" {
+		return errors.New("invalid API version vendor: should not be empty")
+	}
+
+	if cfg.defaultVersion == "" {
+		cfg.defaultVersion = versions[len(versions)-1].name
+	}
+
+	if _, ok := byName[cfg.defaultVersion]; !ok {
+		return fmt.Errorf("invalid default API version: %s (unknown version)", cfg.defaultVersion)
+	}
+
+	l.Mount(route, &acceptVersions{
+		vendor:         cfg.vendor,
+		versions:       byName,
+		defaultVersion: cfg.defaultVersion,
+	})
+
+	return nil
+}
+
+// versionHandler returns the handler of the version, which sets the version response headers.
+func (v *APIVersion) versionHandler() http.Handler {
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(apiVersionHeader, v.name)
+
+		if !v.deprecation.IsZero() {
+			w.Header().Set("Deprecation", "@"+strconv.FormatInt(v.deprecation.Unix(), 10))
+		}
+
+		if !v.sunset.IsZero() {
+			w.Header().Set("Sunset", v.sunset.UTC().Format(http.TimeFormat))
+		}
+
+		if v.link != "" {
+			w.Header().Add("Link", fmt.Sprintf("<%s>; rel=%q", v.link, "deprecation"))
+		}
+
+		v.handler.ServeHTTP(w, r)
+	})
+
+	for i := len(v.middlewares) - 1; i >= 0; i-- {
+		handler = v.middlewares[i](handler)
+	}
+
+	return handler
+}
+
+// acceptVersions dispatches requests to the versions by the Accept request header.
+type acceptVersions struct {
+	vendor         string
+	versions       map[string]http.Handler
+	defaultVersion string
+}
+
+func (a *acceptVersions) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Accept")
+
+	name, ok := a.negotiate(r.Header.Values("Accept"))
+	if !ok {
+		name = a.defaultVersion
+	}
+
+	handler, ok := a.versions[name]
+	if !ok {
+		// Get log hook from the context to set an error which
+		// will be logged along with access log line.
+		if hook := ctxkit.GetLogErrHook(r.Context()); hook != nil {
+			hook(fmt.Errorf("unknown API version: %s", name))
+		}
+
+		http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+
+		return
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// negotiate returns the version requested by the Accept header values.
+// Returns false if none of the media ranges requests the version.
+func (a *acceptVersions) negotiate(accept []string) (string, bool) {
+	vendorPrefix := "application/vnd." + a.vendor + "."
+
+	for _, value := range accept {
+		for _, mediaRange := range strings.Split(value, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil {
+				continue
+			}
+
+			if version, ok := params[versionParam]; ok {
+				return version, true
+			}
+
+			if version, ok := strings.CutPrefix(mediaType, vendorPrefix); ok {
+				version, _, _ = strings.Cut(version, "+")
+				return version, true
+			}
+		}
+	}
+
+	return "", false
+}
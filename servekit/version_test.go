@@ -0,0 +1,127 @@
+package servekit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func versionHandler(body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(body))
+	})
+}
+
+func TestListenerHTTP_MountVersions(t *testing.T) {
+	deprecation := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	versions := func() []*APIVersion {
+		return []*APIVersion{
+			Version("v1", versionHandler("v1"),
+				VersionDeprecated(deprecation),
+				VersionSunset(sunset),
+				VersionLink("https://example.com/migration"),
+			),
+			Version("v2", versionHandler("v2"), VersionMiddlewares(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("X-Middleware", "applied")
+					next.ServeHTTP(w, r)
+				})
+			})),
+		}
+	}
+
+	do := func(l *ListenerHTTP, target, accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+
+		w := httptest.NewRecorder()
+		l.server.Handler.ServeHTTP(w, r)
+
+		return w
+	}
+
+	t.Run("Prefix", func(t *testing.T) {
+		l, err := New(":0")
+		td.Require(t).CmpNoError(err)
+		td.Require(t).CmpNoError(l.MountVersions("/api", versions()))
+
+		resp := do(l, "/api/v1/items", "")
+		td.Cmp(t, resp.Body.String(), "v1")
+		td.Cmp(t, resp.Header(), td.SuperMapOf(http.Header{
+			"Api-Version": {"v1"},
+			"Deprecation": {"@1704067200"},
+			"Sunset":      {"Wed, 01 Jan 2025 00:00:00 GMT"},
+			"Link":        {`<https://example.com/migration>; rel="deprecation"`},
+		}, nil))
+
+		resp = do(l, "/api/v2/items", "")
+		td.Cmp(t, resp.Body.String(), "v2")
+		td.Cmp(t, resp.Header().Get("X-Middleware"), "applied")
+		td.Cmp(t, resp.Header().Get("Api-Version"), "v2")
+		td.Cmp(t, resp.Header().Get("Deprecation"), "")
+
+		td.Cmp(t, do(l, "/api/v3/items", "").Code, http.StatusNotFound)
+	})
+
+	t.Run("Accept", func(t *testing.T) {
+		l, err := New(":0")
+		td.Require(t).CmpNoError(err)
+		td.Require(t).CmpNoError(l.MountVersions("/api", versions(), VersionByAccept("bones"), VersionDefault("v1")))
+
+		type tcase struct {
+			accept     string
+			wantStatus int
+			wantBody   string
+		}
+
+		tests := map[string]tcase{
+			"Default":       {accept: "", wantStatus: http.StatusOK, wantBody: "v1"},
+			"NoVersion":     {accept: "application/json", wantStatus: http.StatusOK, wantBody: "v1"},
+			"VendorType":    {accept: "application/vnd.bones.v2+json", wantStatus: http.StatusOK, wantBody: "v2"},
+			"Parameter":     {accept: "text/html, application/json; version=v2", wantStatus: http.StatusOK, wantBody: "v2"},
+			"OtherVendor":   {accept: "application/vnd.other.v2+json", wantStatus: http.StatusOK, wantBody: "v1"},
+			"UnknownVendor": {accept: "application/vnd.bones.v3+json", wantStatus: http.StatusNotAcceptable, wantBody: "Not Acceptable\n"},
+		}
+
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				resp := do(l, "/api/items", tc.accept)
+				td.Cmp(t, resp.Code, tc.wantStatus)
+				td.Cmp(t, resp.Body.String(), tc.wantBody)
+				td.Cmp(t, resp.Header().Get("Vary"), "Accept")
+			})
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		type tcase struct {
+			versions []*APIVersion
+			options  []Option[*VersioningConfig]
+			wantErr  string
+		}
+
+		tests := map[string]tcase{
+			"Empty":          {versions: nil, wantErr: "invalid API versions: should not be empty"},
+			"EmptyName":      {versions: []*APIVersion{Version("", versionHandler(""))}, wantErr: `invalid API version name: "" (should not be empty or contain '/' slash)`},
+			"NilHandler":     {versions: []*APIVersion{Version("v1", nil)}, wantErr: "invalid API version v1: handler is nil"},
+			"Duplicated":     {versions: append(versions(), Version("v1", versionHandler(""))), wantErr: "invalid API version v1: duplicated"},
+			"EmptyVendor":    {versions: versions(), options: []Option[*VersioningConfig]{VersionByAccept("")}, wantErr: "invalid API version vendor: should not be empty"},
+			"UnknownDefault": {versions: versions(), options: []Option[*VersioningConfig]{VersionByAccept("bones"), VersionDefault("v3")}, wantErr: "invalid default API version: v3 (unknown version)"},
+		}
+
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				l, err := New(":0")
+				td.Require(t).CmpNoError(err)
+				td.CmpString(t, l.MountVersions("/api", tc.versions, tc.options...), tc.wantErr)
+			})
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package servekit
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// mountWildcard matches the wildcard segments added by ListenerHTTP.Mount
+// in the middle of the route pattern, e.g. "/api/*/items".
+var mountWildcard = regexp.MustCompile(`/\*(/|$)`)
+
+// route represents an endpoint registered on the router.
+type route struct {
+	method      string
+	pattern     string
+	handler     http.Handler
+	middlewares []Middleware
+}
+
+// walkRoutes returns all endpoints registered on the router sorted by pattern and method.
+func walkRoutes(router chi.Routes) ([]route, error) {
+	routes := make([]route, 0)
+
+	walkFn := func(method, pattern string, handler http.Handler, middlewares ...Middleware) error {
+		routes = append(routes, route{
+			method:      method,
+			pattern:     normalizePattern(pattern),
+			handler:     handler,
+			middlewares: middlewares,
+		})
+
+		return nil
+	}
+
+	if err := chi.Walk(router, walkFn); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].pattern == routes[j].pattern {
+			return routes[i].method < routes[j].method
+		}
+
+		return routes[i].pattern < routes[j].pattern
+	})
+
+	return routes, nil
+}
+
+// normalizePattern removes the wildcard segments produced by sub-routers mounted with
+// ListenerHTTP.Mount, e.g. "/api/*/items" becomes "/api/items". Wildcard at the end of
+// the pattern is kept since it matches the handler mounted with all its sub-paths.
+func normalizePattern(pattern string) string {
+	if strings.HasSuffix(pattern, "/*") && !strings.HasSuffix(pattern, "/*/") {
+		return normalizePattern(strings.TrimSuffix(pattern, "/*")) + "/*"
+	}
+
+	pattern = mountWildcard.ReplaceAllString(pattern, "/")
+
+	for strings.Contains(pattern, "//") {
+		pattern = strings.ReplaceAll(pattern, "//", "/")
+	}
+
+	if len(pattern) > 1 {
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+
+	return pattern
+}
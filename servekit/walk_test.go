@@ -0,0 +1,23 @@
+package servekit
+
+import (
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestNormalizePattern(t *testing.T) {
+	tests := map[string]string{
+		"/":                      "/",
+		"/api/*/":                "/api",
+		"/api/*/items":           "/api/items",
+		"/api/*/v1/*/items/{id}": "/api/v1/items/{id}",
+		"/raw/*":                 "/raw/*",
+		"/api/*/static/*":        "/api/static/*",
+		"/debug/pprof/":          "/debug/pprof",
+	}
+
+	for pattern, want := range tests {
+		td.Cmp(t, normalizePattern(pattern), want, pattern)
+	}
+}
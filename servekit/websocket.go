@@ -0,0 +1,240 @@
+package servekit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// websocketCloseTimeout represents the time given to send the close message to the client.
+const websocketCloseTimeout = time.Second
+
+// WebSocketConfig represents configuration of the WebSocket connections.
+type WebSocketConfig struct {
+	origins      []string
+	subprotocols []string
+	pingInterval time.Duration
+	readLimit    int64
+	compression  bool
+}
+
+// WebSocketOrigins represents an optional function for WebSocketHandler function.
+// If passed to the WebSocketHandler, will add the given origins to the config.origins,
+// which are allowed to open the connection in addition to the same origin. Origin '*'
+// allows any origin, and origins starting with '*.' allow any subdomain.
+func WebSocketOrigins(origins ...string) Option[*WebSocketConfig] {
+	return func(c *WebSocketConfig) { c.origins = append(c.origins, origins...) }
+}
+
+// WebSocketSubprotocols represents an optional function for WebSocketHandler function.
+// If passed to the WebSocketHandler, will set the config.subprotocols supported by the server
+// in order of preference. Use websocket.Conn.Subprotocol to get the negotiated one.
+func WebSocketSubprotocols(subprotocols ...string) Option[*WebSocketConfig] {
+	return func(c *WebSocketConfig) { c.subprotocols = subprotocols }
+}
+
+// WebSocketPingInterval represents an optional function for WebSocketHandler function.
+// If passed to the WebSocketHandler, will set the config.pingInterval, which is the
+// interval of pings sent to keep the idle connection open. Zero turns off the pings.
+func WebSocketPingInterval(interval time.Duration) Option[*WebSocketConfig] {
+	return func(c *WebSocketConfig) { c.pingInterval = interval }
+}
+
+// WebSocketReadLimit represents an optional function for WebSocketHandler function.
+// If passed to the WebSocketHandler, will set the config.readLimit, which is
+// the maximum size in bytes of the message read from the client.
+func WebSocketReadLimit(limit int64) Option[*WebSocketConfig] {
+	return func(c *WebSocketConfig) { c.readLimit = limit }
+}
+
+// WebSocketCompression represents an optional function for WebSocketHandler function.
+// If passed to the WebSocketHandler, will turn on the per message compression negotiation.
+func WebSocketCompression(enable bool) Option[*WebSocketConfig] {
+	return func(c *WebSocketConfig) { c.compression = enable }
+}
+
+// WebSocketHandler returns the handler which upgrades the request
+// to the WebSocket connection and passes it to fn.
+// Receives the following options to configure the connections:
+// - WebSocketOrigins - to allow cross-origin connections.
+// - WebSocketSubprotocols - to negotiate the subprotocol.
+// - WebSocketPingInterval - to set the interval of pings.
+// - WebSocketReadLimit - to limit the size of messages.
+// - WebSocketCompression - to negotiate the compression.
+//
+// The ctx passed to fn is canceled when the listener starts shutting down. Then the
+// connection is closed with 1001 (going away) code, which makes reads in fn fail, and
+// the listener waits for fn to return. The connection is closed when fn returns.
+//
+// Example:
+//
+//	router.Method(http.MethodGet, "/ws", servekit.WebSocketHandler(func(ctx context.Context, conn *websocket.Conn) error {
+//		for {
+//			kind, msg, err := conn.ReadMessage()
+//			if err != nil {
+//				return err
+//			}
+//
+//			if err := conn.WriteMessage(kind, msg); err != nil {
+//				return err
+//			}
+//		}
+//	}))
+func WebSocketHandler(fn func(ctx context.Context, conn *websocket.Conn) error, options ...Option[*WebSocketConfig]) http.Handler {
+	cfg := WebSocketConfig{
+		origins:      make([]string, 0),
+		subprotocols: make([]string, 0),
+	}
+
+	for _, opt := range options {
+		opt(&cfg)
+	}
+
+	h := websocketHandler{fn: fn, cfg: cfg}
+
+	h.upgrader = websocket.Upgrader{
+		Subprotocols:      cfg.subprotocols,
+		EnableCompression: cfg.compression,
+		CheckOrigin:       h.checkOrigin,
+		Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+			reportError(r, reason)
+			http.Error(w, http.StatusText(status), status)
+		},
+	}
+
+	return &h
+}
+
+type websocketHandler struct {
+	fn       func(ctx context.Context, conn *websocket.Conn) error
+	cfg      WebSocketConfig
+	upgrader websocket.Upgrader
+}
+
+func (h *websocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tracker, _ := r.Context().Value(streamsKey{}).(*streams)
+
+	// Register the connection before the upgrade, so the listener
+	// shutdown does not miss the connection being hijacked.
+	if tracker != nil {
+		select {
+		case <-tracker.done:
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+
+		default:
+			tracker.hijacked.Add(1)
+			defer tracker.hijacked.Done()
+		}
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already responded with the error.
+		return
+	}
+
+	// The hijacked connection keeps the deadlines set by http.Server.
+	if err := conn.NetConn().SetDeadline(time.Time{}); err != nil {
+		reportError(r, err)
+		conn.Close()
+
+		return
+	}
+
+	if h.cfg.readLimit > 0 {
+		conn.SetReadLimit(h.cfg.readLimit)
+	}
+
+	ctx, cancel := streamContext(r.Context())
+	defer cancel()
+
+	done := make(chan struct{})
+	closed := make(chan struct{})
+
+	go func() {
+		defer close(closed)
+		h.keepalive(ctx, conn, done)
+	}()
+
+	// Errors caused by closing the connection on shutdown are expected.
+	if err := h.fn(ctx, conn); err != nil && ctx.Err() == nil && !isWebSocketClosed(err) {
+		reportError(r, err)
+	}
+
+	close(done)
+	<-closed
+
+	conn.Close()
+}
+
+// keepalive pings the connection until done is closed, and closes
+// the connection with 1001 (going away) code when the ctx is done.
+func (h *websocketHandler) keepalive(ctx context.Context, conn *websocket.Conn, done <-chan struct{}) {
+	var tick <-chan time.Time
+
+	if h.cfg.pingInterval > 0 {
+		ticker := time.NewTicker(h.cfg.pingInterval)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+
+		case <-ctx.Done():
+			msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server is shutting down")
+			_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(websocketCloseTimeout))
+			conn.Close()
+
+			return
+
+		case <-tick:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(h.cfg.pingInterval)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// checkOrigin reports whether the request origin is allowed to open the connection.
+func (h *websocketHandler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	return slices.ContainsFunc(h.cfg.origins, func(allowed string) bool {
+		if allowed == "*" || strings.EqualFold(allowed, origin) || strings.EqualFold(allowed, u.Host) {
+			return true
+		}
+
+		suffix, ok := strings.CutPrefix(allowed, "*")
+
+		return ok && strings.HasSuffix(strings.ToLower(u.Hostname()), strings.ToLower(suffix))
+	})
+}
+
+// isWebSocketClosed reports whether the err is caused by the normally closed connection.
+func isWebSocketClosed(err error) bool {
+	return websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) ||
+		errors.Is(err, websocket.ErrCloseSent)
+}
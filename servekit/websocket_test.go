@@ -0,0 +1,112 @@
+package servekit
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/heartwilltell/bones/servekit/middleware"
+	"github.com/heartwilltell/log"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestWebSocketHandler(t *testing.T) {
+	l, err := New("", WithWriteTimeout(300*time.Millisecond), WithGlobalMiddlewares(
+		middleware.LoggingMiddleware(log.NewNopLog()),
+		middleware.MetricsMiddleware(),
+	))
+	td.Require(t).CmpNoError(err)
+
+	l.Mount("/ws", WebSocketHandler(func(_ context.Context, conn *websocket.Conn) error {
+		for {
+			kind, msg, err := conn.ReadMessage()
+			if err != nil {
+				return err
+			}
+
+			if err := conn.WriteMessage(kind, msg); err != nil {
+				return err
+			}
+		}
+	}, WebSocketOrigins("*.example.com"), WebSocketSubprotocols("echo"), WebSocketPingInterval(50*time.Millisecond)))
+
+	url, stop := startListener(t, l)
+	url = "ws" + strings.TrimPrefix(url, "http") + "/ws"
+
+	t.Run("Origin", func(t *testing.T) {
+		type tcase struct {
+			origin     string
+			wantStatus int
+		}
+
+		tests := map[string]tcase{
+			"None":      {origin: "", wantStatus: http.StatusSwitchingProtocols},
+			"Allowed":   {origin: "https://app.example.com", wantStatus: http.StatusSwitchingProtocols},
+			"Forbidden": {origin: "https://evil.com", wantStatus: http.StatusForbidden},
+		}
+
+		for name, tc := range tests {
+			t.Run(name, func(t *testing.T) {
+				header := http.Header{}
+				if tc.origin != "" {
+					header.Set("Origin", tc.origin)
+				}
+
+				conn, resp, _ := websocket.DefaultDialer.Dial(url, header)
+				if conn != nil {
+					conn.Close()
+				}
+
+				td.Require(t).NotNil(resp)
+				td.Cmp(t, resp.StatusCode, tc.wantStatus)
+			})
+		}
+	})
+
+	dialer := websocket.Dialer{Subprotocols: []string{"echo"}}
+
+	conn, _, err := dialer.Dial(url, nil)
+	td.Require(t).CmpNoError(err)
+
+	defer conn.Close()
+
+	td.Cmp(t, conn.Subprotocol(), "echo")
+
+	pinged := make(chan struct{}, 1)
+	conn.SetPingHandler(func(string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+
+		return nil
+	})
+
+	// The connection outlives the write timeout.
+	for _, msg := range []string{"hello", "world"} {
+		time.Sleep(200 * time.Millisecond)
+
+		td.Require(t).CmpNoError(conn.WriteMessage(websocket.TextMessage, []byte(msg)))
+
+		_, got, err := conn.ReadMessage()
+		td.Require(t).CmpNoError(err)
+		td.Cmp(t, string(got), msg)
+	}
+
+	select {
+	case <-pinged:
+	default:
+		t.Error("ping is not received")
+	}
+
+	// The connection is closed by the listener shutdown without waiting for the shutdown timeout.
+	start := time.Now()
+	td.CmpNoError(t, stop())
+	td.Cmp(t, time.Since(start), td.Lt(time.Second))
+
+	_, _, err = conn.ReadMessage()
+	td.CmpTrue(t, websocket.IsCloseError(err, websocket.CloseGoingAway), err)
+}